@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
@@ -65,22 +66,46 @@ func main() {
 	service := releases.NewHipHopService(repo, releaseFetcher, eventFetcher)
 
 	// init updater
+	yearsWindow, err := updater.ParseYearsWindow(
+		os.Getenv("UPDATER_YEARS"),
+		os.Getenv("UPDATER_YEARS_BACK"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
 	updater := updater.NewUpdater(service, repo)
+	updater.Window = yearsWindow
 
 	// prepare context
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// init bot
-	bot := bot.NewTGBot(os.Getenv("TG_BOT_TOKEN"), service, updater)
-	if bot == nil {
-		log.Fatal("bot nil, the end")
+	// init bot, updates come by webhook if its url is set, otherwise by long polling
+	webhook := bot.WebhookConfig{
+		URL:      os.Getenv("WEBHOOK_URL"),
+		Listen:   os.Getenv("WEBHOOK_LISTEN"),
+		Secret:   os.Getenv("WEBHOOK_SECRET"),
+		CertFile: os.Getenv("WEBHOOK_CERT"),
+		KeyFile:  os.Getenv("WEBHOOK_KEY"),
 	}
+	bot, err := bot.NewTGBot(os.Getenv("TG_BOT_TOKEN"), service, updater)
+	if err != nil {
+		log.Fatal(err)
+	}
+	updater.Notifier = bot
 
 	timeForUpdate := time.Duration(8 * time.Hour)
 	// start goroutines with update releases and tg-bot
-	go updater.StartUploadReleases(ctx, timeForUpdate, []int{2023, 2024}, false)
-	go bot.Start(ctx, 30)
-	go bot.SendEventAndReleasesEveryday(ctx)
+	go updater.StartUploadReleases(ctx, timeForUpdate, false)
+	if webhook.Enabled() {
+		go func() {
+			if err := bot.StartWebhook(ctx, webhook); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	} else {
+		go bot.Start(ctx, 30)
+	}
+	go bot.StartDigestScheduler(ctx)
 
 	// chan for os signals
 	sigCh := make(chan os.Signal, 1)
@@ -101,8 +126,9 @@ func main() {
 //
 // Функция выполняет следующие действия:
 // 1. Открывает соединение с базой данных SQLite по указанному пути.
-// 2. Устанавливает диалект базы данных для goose как "sqlite3".
-// 3. Применяет все миграции, найденные в указанной директории миграций.
+// 2. Проверяет, что sqlite собран с FTS5, который нужен миграции поиска.
+// 3. Устанавливает диалект базы данных для goose как "sqlite3".
+// 4. Применяет все миграции, найденные в указанной директории миграций.
 //
 // В случае возникновения ошибки при открытии соединения с базой данных или
 // при применении миграций, функция возвращает ошибку, обернутую с дополнительным
@@ -113,6 +139,10 @@ func migrationDBUp(path string, migrationsPath string) error {
 		return fmt.Errorf("error while connecting to test db: %w", err)
 	}
 
+	if err = sqlite.CheckFTS5(db); err != nil {
+		return err
+	}
+
 	goose.SetDialect("sqlite3")
 	err = goose.Up(db.DB, migrationsPath)
 	if err != nil {
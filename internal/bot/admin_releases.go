@@ -0,0 +1,340 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+// ReleaseFields - поля релиза, которые администратор может исправить через /editrelease.
+var ReleaseFields = []string{
+	ReleaseTitleField,
+	ReleaseArtistField,
+	ReleaseTypeField,
+	ReleaseDateField,
+	ReleaseCoverField,
+}
+
+func ParseReleaseType(value string) (models.ReleaseType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case AlbumTypeArg:
+		return models.Album, nil
+	case SingleTypeArg:
+		return models.Single, nil
+	}
+	return 0, fmt.Errorf("unknown release type %q, expected %s or %s", value, AlbumTypeArg, SingleTypeArg)
+}
+
+func ParseReleaseDate(value string) (time.Time, error) {
+	date, err := time.Parse(ReleaseDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected %s", value, ReleaseDateLayout)
+	}
+	return date, nil
+}
+
+func ParseCoverUrl(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	coverUrl, err := url.ParseRequestURI(value)
+	if err != nil || (coverUrl.Scheme != "http" && coverUrl.Scheme != "https") {
+		return "", fmt.Errorf("invalid cover url %q", value)
+	}
+	return value, nil
+}
+
+// ParseAddReleaseArgs разбирает "Исполнитель - Название; 2026-10-18; album; ссылка на обложку",
+// обложка необязательна.
+func ParseAddReleaseArgs(args string) (models.Release, error) {
+	parts := strings.Split(args, ";")
+	if len(parts) != 3 && len(parts) != 4 {
+		return models.Release{}, errors.New("expected 3 or 4 parts separated by ;")
+	}
+
+	artist, title, ok := strings.Cut(parts[0], " - ")
+	if !ok {
+		artist, title, ok = strings.Cut(parts[0], " – ")
+	}
+	artist, title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	if !ok || artist == "" || title == "" {
+		return models.Release{}, fmt.Errorf("expected \"Artist - Title\", got %q", strings.TrimSpace(parts[0]))
+	}
+
+	date, err := ParseReleaseDate(parts[1])
+	if err != nil {
+		return models.Release{}, err
+	}
+
+	releaseType, err := ParseReleaseType(parts[2])
+	if err != nil {
+		return models.Release{}, err
+	}
+
+	release := models.Release{
+		Artist:  models.Artist{Name: artist},
+		Title:   title,
+		Type:    releaseType,
+		OutDate: types.NewCustomDate(date.Year(), date.Month(), date.Day()),
+	}
+	if len(parts) == 4 && strings.TrimSpace(parts[3]) != "" {
+		coverUrl, err := ParseCoverUrl(parts[3])
+		if err != nil {
+			return models.Release{}, err
+		}
+		release.CoverUrl = models.CoverUrl{Value: coverUrl, IsValid: true}
+	}
+
+	return release, nil
+}
+
+// ParseEditReleaseArgs разбирает "id поле значение", значение может содержать пробелы.
+func ParseEditReleaseArgs(args string) (int, string, string, error) {
+	idArg, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	releaseId, err := strconv.Atoi(idArg)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid release id %q", idArg)
+	}
+
+	field, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	field, value = strings.ToLower(field), strings.TrimSpace(value)
+	if !slices.Contains(ReleaseFields, field) {
+		return 0, "", "", fmt.Errorf("unknown field %q", field)
+	}
+	if value == "" {
+		return 0, "", "", fmt.Errorf("empty value for field %s", field)
+	}
+
+	return releaseId, field, value, nil
+}
+
+// ParseMergeArtistsArgs разбирает "Дубликат; Исполнитель".
+func ParseMergeArtistsArgs(args string) (string, string, error) {
+	from, to, ok := strings.Cut(args, ";")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !ok || from == "" || to == "" {
+		return "", "", errors.New("expected two artists separated by ;")
+	}
+	if strings.EqualFold(from, to) {
+		return "", "", errors.New("artists are the same")
+	}
+
+	return from, to, nil
+}
+
+// ParseRefreshPeriod разбирает год (2025) или месяц (2025-03), для года month = 0.
+func ParseRefreshPeriod(args string) (int, time.Month, error) {
+	args = strings.TrimSpace(args)
+	if year, err := strconv.Atoi(args); err == nil && year > 0 {
+		return year, 0, nil
+	}
+
+	date, err := time.Parse(RefreshMonthLayout, args)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid period %q, expected year or %s", args, RefreshMonthLayout)
+	}
+	return date.Year(), date.Month(), nil
+}
+
+// RefreshCommandHandler без аргументов обновляет релизы за окно лет апдейтера,
+// иначе - за указанный год или месяц.
+func (b *TGBot) RefreshCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	args := strings.TrimSpace(upd.Message.CommandArguments())
+	if args == "" {
+		b.RefreshReleasesHandler(user, b.Updater.CurrentYears())
+		return nil
+	}
+
+	year, month, err := ParseRefreshPeriod(args)
+	if err != nil {
+		b.sendInvalidAdminArgs(user, err, RefreshUsageMessage, JoinYears(b.Updater.CurrentYears()))
+		return nil
+	}
+	if month == 0 {
+		b.RefreshReleasesHandler(user, []int{year})
+		return nil
+	}
+
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, RefreshReleasesStartMessageText, args)))
+	b.Updater.RefreshMonth(year, month)
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, RefreshReleasesEndMessageText)))
+
+	return nil
+}
+
+func (b *TGBot) AddReleaseCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	args := strings.TrimSpace(upd.Message.CommandArguments())
+	if args == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, AddReleaseUsageMessage)))
+		return nil
+	}
+
+	release, err := ParseAddReleaseArgs(args)
+	if err != nil {
+		b.sendInvalidAdminArgs(user, err, AddReleaseUsageMessage)
+		return nil
+	}
+
+	releaseId, err := b.Service.AddManualRelease(release)
+	if err != nil {
+		return fmt.Errorf("error while adding release %s: %w", release, err)
+	}
+	log.Printf("admin added release %d: %s", releaseId, release)
+
+	return b.sendAdminRelease(user, releaseId, ReleaseAddedMessage)
+}
+
+func (b *TGBot) EditReleaseCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	args := strings.TrimSpace(upd.Message.CommandArguments())
+	if args == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, EditReleaseUsageMessage)))
+		return nil
+	}
+
+	releaseId, field, value, err := ParseEditReleaseArgs(args)
+	if err != nil {
+		b.sendInvalidAdminArgs(user, err, EditReleaseUsageMessage)
+		return nil
+	}
+
+	if _, err = b.Service.GetRelease(releaseId); err != nil {
+		if errors.Is(err, sqlite.ErrReleasesNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, AdminReleaseNotFoundMessage, releaseId)))
+			return nil
+		}
+		return fmt.Errorf("error while getting release %d: %w", releaseId, err)
+	}
+
+	switch field {
+	case ReleaseTitleField:
+		err = b.Service.UpdateReleaseTitle(releaseId, value)
+	case ReleaseArtistField:
+		err = b.Service.SetReleaseArtist(releaseId, value)
+	case ReleaseTypeField:
+		var releaseType models.ReleaseType
+		if releaseType, err = ParseReleaseType(value); err != nil {
+			b.sendInvalidAdminArgs(user, err, EditReleaseUsageMessage)
+			return nil
+		}
+		err = b.Service.UpdateReleaseType(releaseId, releaseType)
+	case ReleaseDateField:
+		var date time.Time
+		if date, err = ParseReleaseDate(value); err != nil {
+			b.sendInvalidAdminArgs(user, err, EditReleaseUsageMessage)
+			return nil
+		}
+		err = b.Service.UpdateReleaseDate(releaseId, date)
+	case ReleaseCoverField:
+		var coverUrl string
+		if coverUrl, err = ParseCoverUrl(value); err != nil {
+			b.sendInvalidAdminArgs(user, err, EditReleaseUsageMessage)
+			return nil
+		}
+		err = b.Service.UpdateReleaseCoverUrl(releaseId, coverUrl)
+	}
+	if err != nil {
+		return fmt.Errorf("error while updating %s of release %d: %w", field, releaseId, err)
+	}
+	log.Printf("admin updated %s of release %d to %s", field, releaseId, value)
+
+	return b.sendAdminRelease(user, releaseId, ReleaseUpdatedMessage)
+}
+
+func (b *TGBot) DeleteReleaseCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	args := strings.TrimSpace(upd.Message.CommandArguments())
+	if args == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, DeleteReleaseUsageMessage)))
+		return nil
+	}
+
+	releaseId, err := strconv.Atoi(args)
+	if err != nil {
+		b.sendInvalidAdminArgs(user, fmt.Errorf("invalid release id %q", args), DeleteReleaseUsageMessage)
+		return nil
+	}
+
+	if err = b.Service.DeleteRelease(releaseId); err != nil {
+		if errors.Is(err, sqlite.ErrReleasesNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, AdminReleaseNotFoundMessage, releaseId)))
+			return nil
+		}
+		return fmt.Errorf("error while deleting release %d: %w", releaseId, err)
+	}
+	log.Printf("admin deleted release %d", releaseId)
+
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ReleaseDeletedMessage, releaseId)))
+
+	return nil
+}
+
+func (b *TGBot) MergeArtistsCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	args := strings.TrimSpace(upd.Message.CommandArguments())
+	if args == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, MergeArtistsUsageMessage)))
+		return nil
+	}
+
+	fromName, toName, err := ParseMergeArtistsArgs(args)
+	if err != nil {
+		b.sendInvalidAdminArgs(user, err, MergeArtistsUsageMessage)
+		return nil
+	}
+
+	var artistIds [2]int
+	for i, name := range []string{fromName, toName} {
+		artist, err := b.Service.FindArtistByName(name)
+		if err != nil {
+			if errors.Is(err, sqlite.ErrArtistNotFound) {
+				b.trySend(tgbotapi.NewMessage(user.Id, T(lang, AdminArtistNotFoundMessage, name)))
+				return nil
+			}
+			return fmt.Errorf("error while finding artist %s: %w", name, err)
+		}
+		artistIds[i] = artist.Id
+	}
+
+	if err = b.Service.MergeArtists(artistIds[0], artistIds[1]); err != nil {
+		return fmt.Errorf("error while merging artist %s into %s: %w", fromName, toName, err)
+	}
+	log.Printf("admin merged artist %d into %d", artistIds[0], artistIds[1])
+
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ArtistsMergedMessage, fromName, toName)))
+
+	return nil
+}
+
+func (b *TGBot) sendInvalidAdminArgs(user *models.User, err error, usage i18n.Key, args ...any) {
+	lang := UserLang(user)
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, InvalidAdminArgsMessage, err, T(lang, usage, args...))))
+}
+
+// sendAdminRelease показывает администратору релиз после изменения вместе с его id.
+func (b *TGBot) sendAdminRelease(user *models.User, releaseId int, text i18n.Key) error {
+	lang := UserLang(user)
+	release, err := b.Service.GetRelease(releaseId)
+	if err != nil {
+		return fmt.Errorf("error while getting release %d: %w", releaseId, err)
+	}
+
+	caption := GenerateReleaseCardCaption(lang, *release) + fmt.Sprintf(ReleaseIdText, release.Id)
+	msg := tgbotapi.NewMessage(user.Id, T(lang, text, caption))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.trySend(msg)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestAdminReleaseArgs(t *testing.T) {
+	t.Run("add release", func(t *testing.T) {
+		release, err := ParseAddReleaseArgs(
+			"Kendrick Lamar - GNX; 2024-11-22; Album; https://cover.com/gnx.jpg",
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Release{
+			Artist:   models.Artist{Name: "Kendrick Lamar"},
+			Title:    "GNX",
+			Type:     models.Album,
+			OutDate:  types.NewCustomDate(2024, time.November, 22),
+			CoverUrl: models.CoverUrl{Value: "https://cover.com/gnx.jpg", IsValid: true},
+		}, release)
+
+		release, err = ParseAddReleaseArgs("Doechii – Denial Is a River; 2024-04-12; single")
+		assert.NoError(t, err)
+		assert.Equal(t, "Doechii", release.Artist.Name)
+		assert.Equal(t, models.ReleaseType(models.Single), release.Type)
+		assert.False(t, release.CoverUrl.IsValid)
+	})
+
+	t.Run("invalid add release", func(t *testing.T) {
+		for _, args := range []string{
+			"Kendrick Lamar - GNX",
+			"Kendrick Lamar GNX; 2024-11-22; album",
+			"Kendrick Lamar - GNX; 22.11.2024; album",
+			"Kendrick Lamar - GNX; 2024-11-22; mixtape",
+			"Kendrick Lamar - GNX; 2024-11-22; album; cover.jpg",
+		} {
+			_, err := ParseAddReleaseArgs(args)
+			assert.Error(t, err, args)
+		}
+	})
+
+	t.Run("edit release", func(t *testing.T) {
+		releaseId, field, value, err := ParseEditReleaseArgs("-3 Title  Mr. Morale & the Big Steppers ")
+		assert.NoError(t, err)
+		assert.Equal(t, -3, releaseId)
+		assert.Equal(t, ReleaseTitleField, field)
+		assert.Equal(t, "Mr. Morale & the Big Steppers", value)
+
+		for _, args := range []string{"abc title GNX", "1 label TDE", "1 title"} {
+			_, _, _, err := ParseEditReleaseArgs(args)
+			assert.Error(t, err, args)
+		}
+	})
+
+	t.Run("merge artists", func(t *testing.T) {
+		from, to, err := ParseMergeArtistsArgs(" Jay Z ; JAY-Z")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Jay Z", "JAY-Z"}, []string{from, to})
+
+		for _, args := range []string{"Jay Z", "Jay Z;", "JAY-Z; jay-z"} {
+			_, _, err := ParseMergeArtistsArgs(args)
+			assert.Error(t, err, args)
+		}
+	})
+}
+
+func TestParseRefreshPeriod(t *testing.T) {
+	year, month, err := ParseRefreshPeriod("2025")
+	assert.NoError(t, err)
+	assert.Equal(t, 2025, year)
+	assert.Equal(t, time.Month(0), month)
+
+	year, month, err = ParseRefreshPeriod(" 2025-03 ")
+	assert.NoError(t, err)
+	assert.Equal(t, 2025, year)
+	assert.Equal(t, time.March, month)
+
+	for _, args := range []string{"-1", "2025-13", "March 2025"} {
+		_, _, err := ParseRefreshPeriod(args)
+		assert.Error(t, err, args)
+	}
+
+	assert.Equal(t, "2024, 2025", JoinYears([]int{2024, 2025}))
+}
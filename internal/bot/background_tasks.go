@@ -2,31 +2,43 @@ package bot
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
 )
 
-func (b *TGBot) SendEventAndReleasesEveryday(ctx context.Context) {
-	now := time.Now().Local()
-	send_hour, _ := strconv.Atoi(os.Getenv("SEND_SUBS_HOUR"))
-	send_minute, _ := strconv.Atoi(os.Getenv("SEND_SUBS_MINUTE"))
-	next := time.Date(now.Year(), now.Month(), now.Day(), send_hour, send_minute, 0, 0, time.Local)
+const (
+	DigestTimeLayout    = "15:04"
+	DigestDateLayout    = time.DateOnly
+	DigestGracePeriod   = time.Hour
+	DigestCheckInterval = time.Minute
 
-	if now.After(next) {
-		next = next.Add(24 * time.Hour)
-	}
-	log.Printf("next send to subscribers: %d:%2d %2d.%2d.%d",
-		next.Hour(), next.Minute(),
-		next.Day(), next.Month(), next.Year(),
-	)
+	WeeklyDigestWeekday = time.Monday
+	WeeklyDigestDays    = 7
+)
 
-	time.Sleep(time.Until(next))
+// DigestKinds - подписки, которые доставляются по расписанию во время рассылки.
+var DigestKinds = []models.SubscriptionKind{
+	models.HistorySubscription,
+	models.TodayReleasesSubscription,
+	models.WeeklyDigestSubscription,
+}
 
-	ticker := time.NewTicker(24 * time.Hour)
-	b.SendTodayEventToSubscribers()
-	b.SendTodayReleasesToSubscribers()
+// StartDigestScheduler раз в минуту проверяет подписчиков и отправляет ежедневную
+// рассылку и напоминания о релизах тем, у кого по их часовому поясу наступило время рассылки,
+// и публикует посты в каналы из PUBLISH_CHANNEL_IDS.
+func (b *TGBot) StartDigestScheduler(ctx context.Context) {
+	log.Printf("digest scheduler started, default digest time %s", DefaultDigestTime())
+	ticker := time.NewTicker(DigestCheckInterval)
 	defer ticker.Stop()
 
 	for {
@@ -35,10 +47,417 @@ func (b *TGBot) SendEventAndReleasesEveryday(ctx context.Context) {
 			log.Println("bot timer goroutine closing...")
 			return
 
-		// sending message to subscribers every $DURATION
-		case <-ticker.C:
-			b.SendTodayEventToSubscribers()
-			b.SendTodayReleasesToSubscribers()
+		case now := <-ticker.C:
+			b.SendDueDigests(now)
+			b.SendDueReminders(now)
+			b.PublishDue(now)
+		}
+	}
+}
+
+// DigestRecipient - подписчик или чат и виды рассылки, которые ему нужно отправить.
+// Заполнено ровно одно из полей User и Chat.
+type DigestRecipient struct {
+	User  *models.User
+	Chat  *models.Chat
+	Kinds map[models.SubscriptionKind]bool
+}
+
+func (r *DigestRecipient) ChatId() int64 {
+	if r.Chat != nil {
+		return r.Chat.Id
+	}
+	return r.User.Id
+}
+
+func (r *DigestRecipient) Lang() i18n.Lang {
+	if r.Chat != nil {
+		return ChatLang(r.Chat)
+	}
+	return UserLang(r.User)
+}
+
+func (r *DigestRecipient) Location() *time.Location {
+	if r.Chat != nil {
+		return ChatLocation(r.Chat)
+	}
+	return UserLocation(r.User)
+}
+
+// Filter - фильтр релизов дня, в чатах показываются все типы.
+func (r *DigestRecipient) Filter() models.ReleaseType {
+	if r.Chat != nil {
+		return models.AnyType
+	}
+	return r.User.ReleaseTypeFilter
+}
+
+// IsDue проверяет, пора ли отправить получателю рассылку kind.
+func (r *DigestRecipient) IsDue(kind models.SubscriptionKind, now time.Time) bool {
+	due := false
+	if r.Chat != nil {
+		due = IsChatDigestDue(r.Chat, now)
+	} else {
+		due = IsDigestDue(r.User, now)
+	}
+	if !due {
+		return false
+	}
+
+	return kind != models.WeeklyDigestSubscription ||
+		now.In(r.Location()).Weekday() == WeeklyDigestWeekday
+}
+
+func (b *TGBot) SendDueDigests(now time.Time) {
+	recipients := make(map[int64]*DigestRecipient)
+	for _, kind := range DigestKinds {
+		due, err := b.dueRecipients(kind, now)
+		if err != nil {
+			log.Printf("error while getting %s recipients: %s", kind, err)
+			// проверка идет каждую минуту, одна и та же ошибка приходит раз в AdminAlertInterval
+			text := T(i18n.DefaultLang, ErrorAdminMessage, err)
+			if b.alerts.allow(text, now) {
+				b.notifyAdmin(text)
+			}
+			// остальные виды рассылки отправляются как обычно
+			continue
+		}
+
+		// id пользователей положительные, а групп и каналов - отрицательные
+		for _, dueRecipient := range due {
+			recipient, ok := recipients[dueRecipient.ChatId()]
+			if !ok {
+				recipient = dueRecipient
+				recipient.Kinds = make(map[models.SubscriptionKind]bool)
+				recipients[recipient.ChatId()] = recipient
+			}
+			recipient.Kinds[kind] = true
+		}
+	}
+
+	// у подписчиков из разных часовых поясов "сегодня" может быть разным днем
+	dueByDate := make(map[string][]*DigestRecipient)
+	for _, recipient := range recipients {
+		date := now.In(recipient.Location()).Format(DigestDateLayout)
+		dueByDate[date] = append(dueByDate[date], recipient)
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	run := models.DigestRun{StartedAt: time.Now(), Recipients: len(recipients)}
+	for date, dateRecipients := range dueByDate {
+		run.Failed += b.SendDigest(date, dateRecipients)
+	}
+	run.FinishedAt = time.Now()
+
+	if err := b.Service.AddDigestRun(run); err != nil {
+		log.Printf("error while saving digest run: %s", err)
+	}
+}
+
+// dueRecipients возвращает пользователей и чаты, подписанные на kind,
+// которым пора отправить рассылку.
+func (b *TGBot) dueRecipients(kind models.SubscriptionKind, now time.Time) ([]*DigestRecipient, error) {
+	var due []*DigestRecipient
+
+	subscribers, err := b.Service.GetSubscribers(kind)
+	if err != nil && !errors.Is(err, sqlite.ErrUserNotFound) {
+		return nil, err
+	}
+	for _, subscriber := range subscribers {
+		recipient := &DigestRecipient{User: subscriber}
+		if recipient.IsDue(kind, now) {
+			due = append(due, recipient)
+		}
+	}
+
+	chats, err := b.Service.GetSubscribedChats(kind)
+	if err != nil && !errors.Is(err, sqlite.ErrChatNotFound) {
+		return nil, err
+	}
+	for _, chat := range chats {
+		recipient := &DigestRecipient{Chat: chat}
+		if recipient.IsDue(kind, now) {
+			due = append(due, recipient)
+		}
+	}
+
+	return due, nil
+}
+
+// SendDigest отправляет за date событие из истории хип хопа, релизы за день
+// и релизы на неделю вперед - каждому только то, на что он подписан.
+// Данные запрашиваются один раз на всех подписчиков.
+// Возвращает число получателей, которым рассылку доставить не удалось.
+func (b *TGBot) SendDigest(date string, recipients []*DigestRecipient) int {
+	day, err := time.Parse(DigestDateLayout, date)
+	if err != nil {
+		log.Printf("error while parsing digest date %s: %s", date, err)
+		return len(recipients)
+	}
+
+	wanted := make(map[models.SubscriptionKind]bool)
+	for _, recipient := range recipients {
+		for kind := range recipient.Kinds {
+			wanted[kind] = true
+		}
+	}
+
+	log.Printf("sending digest for %s to %d subscribers", date, len(recipients))
+	var events []*models.TodayPost
+	var eventsErr error
+	if wanted[models.HistorySubscription] {
+		events, eventsErr = b.Service.GetEventsByDate(day)
+	}
+
+	var releases []models.Release
+	if wanted[models.TodayReleasesSubscription] {
+		releases, err = b.Service.GetReleasesByDay(
+			day.Year(), day.Month(), day.Day(),
+			models.AnyType,
+			NoLimit, NoOffset,
+		)
+		if err != nil {
+			log.Printf("error while getting releases for %s: %s", date, err)
+		}
+	}
+
+	var weekReleases []models.Release
+	if wanted[models.WeeklyDigestSubscription] {
+		weekReleases, err = b.Service.GetReleasesByPeriod(
+			day,
+			day.AddDate(0, 0, WeeklyDigestDays-1),
+			StandardReleasesLimit,
+			NoOffset,
+		)
+		if err != nil {
+			log.Printf("error while getting weekly releases for %s: %s", date, err)
+		}
+	}
+
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(len(recipients))
+	for _, recipient := range recipients {
+		go func(recipient *DigestRecipient) {
+			defer wg.Done()
+			chatId, lang := recipient.ChatId(), recipient.Lang()
+
+			var sendErrs []error
+			if recipient.Kinds[models.HistorySubscription] {
+				sendErrs = append(sendErrs, b.EventsHandler(chatId, lang, events, eventsErr))
+			}
+
+			// Если нет релизов то просто ничего не отправляем.
+			// В группах и каналах кнопки списков не работают, туда уходит пост как в канал публикаций
+			if recipient.Kinds[models.TodayReleasesSubscription] && len(releases) != 0 {
+				if recipient.Chat != nil {
+					_, err := b.Send(b.TodayReleasesPost(chatId, lang, day, releases))
+					sendErrs = append(sendErrs, err)
+				} else {
+					sendErrs = append(sendErrs, b.sendTodayReleases(chatId, lang, recipient.Filter(), day))
+				}
+			}
+
+			if recipient.Kinds[models.WeeklyDigestSubscription] && len(weekReleases) != 0 {
+				if recipient.Chat != nil {
+					_, err := b.Send(b.WeeklyReleasesPost(chatId, lang, weekReleases))
+					sendErrs = append(sendErrs, err)
+				} else {
+					sendErrs = append(sendErrs, b.SendListPage(
+						chatId,
+						lang,
+						NewUpcomingView(WeeklyDigestDays),
+						weekReleases,
+					))
+				}
+			}
+
+			if err := errors.Join(sendErrs...); err != nil {
+				log.Printf("error while sending digest to %d: %s", chatId, err)
+				failed.Add(1)
+			}
+			// без даты рассылка повторится на следующей проверке
+			if digestFailed(sendErrs) {
+				return
+			}
+
+			var err error
+			if recipient.Chat != nil {
+				err = b.Service.SetChatLastDigestDate(chatId, date)
+			} else {
+				err = b.Service.SetLastDigestDate(chatId, date)
+			}
+			if err != nil {
+				log.Printf("error while saving digest date for chat %d: %s", chatId, err)
+			}
+		}(recipient)
+	}
+
+	wg.Wait()
+	return int(failed.Load())
+}
+
+// digestFailed - ни одна часть рассылки не дошла, и причина не в блокировке бота.
+func digestFailed(sendErrs []error) bool {
+	if len(sendErrs) == 0 {
+		return false
+	}
+	for _, err := range sendErrs {
+		if Outcome(err) != Failed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SendDueReminders отправляет напоминания о релизах, которые по часовому поясу пользователя
+// уже вышли, в его время рассылки. Отправленное напоминание удаляется. Напоминание, пропущенное
+// пока бот не работал, приходит с опозданием в ближайшее время рассылки: о вышедшем релизе
+// пользователю все равно полезно узнать.
+func (b *TGBot) SendDueReminders(now time.Time) {
+	// в самом восточном часовом поясе уже может наступить завтра
+	reminders, err := b.Service.GetDueReminders(now.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("error while getting due reminders: %s", err)
+		return
+	}
+
+	releases := make(map[int]*models.Release)
+	for _, reminder := range reminders {
+		release, ok := releases[reminder.ReleaseId]
+		if !ok {
+			release, err = b.Service.GetRelease(reminder.ReleaseId)
+			if err != nil {
+				log.Printf("error while getting release %d for reminder: %s", reminder.ReleaseId, err)
+				continue
+			}
+			releases[reminder.ReleaseId] = release
+		}
+
+		if !IsReminderDue(reminder, *release, now) {
+			continue
+		}
+		if err := b.SendReminder(reminder.User, *release); err != nil {
+			log.Printf("error while reminding user %d about release %d: %s", reminder.User.Id, release.Id, err)
+			// проверка идет каждую минуту, без даты попытки ошибка повторялась бы весь час рассылки
+			date := now.In(UserLocation(reminder.User)).Format(DigestDateLayout)
+			if err := b.Service.SetReminderAttemptDate(reminder.User.Id, release.Id, date); err != nil {
+				log.Printf("error while saving reminder attempt of user %d: %s", reminder.User.Id, err)
+			}
+			continue
+		}
+
+		if err := b.Service.DeleteReminder(reminder.User.Id, release.Id); err != nil {
+			log.Printf("error while deleting reminder of user %d: %s", reminder.User.Id, err)
+		}
+	}
+}
+
+// SendReminder присылает карточку вышедшего релиза с обложкой.
+func (b *TGBot) SendReminder(user *models.User, release models.Release) error {
+	lang := UserLang(user)
+	artist, err := b.Service.GetArtistByName(release.Artist.Name)
+	if err != nil {
+		return fmt.Errorf("error while getting artist of release %d: %w", release.Id, err)
+	}
+	state := ReleaseCardState{}
+	state.IsFollowing, err = b.isFollowing(user, artist.Id)
+	if err != nil {
+		log.Printf("error while getting followed artists: %s", err)
+	}
+	state.IsSaved, err = b.Service.IsReleaseSaved(user.Id, release.Id)
+	if err != nil {
+		log.Printf("error while checking saved release %d: %s", release.Id, err)
+	}
+
+	photoUrl := newReleasesPicUrl
+	if release.CoverUrl.IsValid {
+		photoUrl = release.CoverUrl.Value
+	}
+	msg := tgbotapi.NewPhoto(user.Id, tgbotapi.FileURL(photoUrl))
+	msg.Caption = fmt.Sprintf(
+		"%s\n\n%s",
+		T(lang, ReminderMessage),
+		GenerateReleaseCardCaption(lang, release),
+	)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = GenerateReleaseCardKeyboard(lang, release, artist, state)
+
+	_, err = b.Send(msg)
+	return err
+}
+
+// NotifyUpdaterError сообщает администратору, что обновление релизов прошло с ошибками.
+func (b *TGBot) NotifyUpdaterError(err error) {
+	b.notifyAdmin(T(i18n.DefaultLang, UpdaterErrorAdminMessage, err))
+}
+
+// NotifyFollowers рассылает новые релизы пользователям, которые следят за их исполнителями.
+func (b *TGBot) NotifyFollowers(releases []models.Release) {
+	log.Printf("notifying followers about %d new releases", len(releases))
+	alertSubscribers, err := b.Service.GetSubscribers(models.ArtistAlertsSubscription)
+	if err != nil {
+		if !errors.Is(err, sqlite.ErrUserNotFound) {
+			log.Printf("error while getting artist alerts subscribers: %s", err)
+		}
+		return
+	}
+	withAlerts := make(map[int64]bool, len(alertSubscribers))
+	for _, subscriber := range alertSubscribers {
+		withAlerts[subscriber.Id] = true
+	}
+
+	for _, newRelease := range releases {
+		release, err := b.Service.GetRelease(newRelease.Id)
+		if err != nil {
+			log.Printf("error while getting new release %d: %s", newRelease.Id, err)
+			continue
+		}
+
+		artist, err := b.Service.GetArtistByName(release.Artist.Name)
+		if err != nil {
+			log.Printf("error while getting artist of release %d: %s", release.Id, err)
+			continue
+		}
+
+		followers, err := b.Service.GetArtistFollowers(artist.Id)
+		if err != nil {
+			if !errors.Is(err, sqlite.ErrUserNotFound) {
+				log.Printf("error while getting followers of %s: %s", artist.Name, err)
+			}
+			continue
+		}
+
+		photoUrl := newReleasesPicUrl
+		if release.CoverUrl.IsValid {
+			photoUrl = release.CoverUrl.Value
+		}
+
+		for _, follower := range followers {
+			if !withAlerts[follower.Id] {
+				continue
+			}
+
+			lang := UserLang(follower)
+			msg := tgbotapi.NewPhoto(follower.Id, tgbotapi.FileURL(photoUrl))
+			msg.Caption = fmt.Sprintf(
+				"%s\n\n%s",
+				T(lang, NewFollowedReleaseMessage),
+				GenerateReleaseCardCaption(lang, *release),
+			)
+			msg.ParseMode = tgbotapi.ModeHTML
+			msg.ReplyMarkup = GenerateReleaseCardKeyboard(lang, *release, artist, ReleaseCardState{
+				IsFollowing: true,
+				CanRemind:   IsUpcomingRelease(*release, UserNow(follower)),
+			})
+
+			if _, err := b.Send(msg); err != nil {
+				log.Printf("error while notifying user %d about release %d: %s", follower.Id, release.Id, err)
+			}
 		}
 	}
 }
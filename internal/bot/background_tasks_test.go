@@ -0,0 +1,266 @@
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestChatDigest(t *testing.T) {
+	now := time.Date(2024, time.August, 12, 10, 15, 0, 0, time.UTC)
+
+	t.Run("chat settings are used for due check", func(t *testing.T) {
+		chat := &models.Chat{Id: -100, Timezone: "Europe/Berlin", DigestTime: "12:00"}
+		assert.True(t, IsChatDigestDue(chat, now))
+
+		chat.LastDigestDate = "2024-08-12"
+		assert.False(t, IsChatDigestDue(chat, now))
+	})
+
+	t.Run("weekly digest only on monday", func(t *testing.T) {
+		recipient := &DigestRecipient{
+			Chat: &models.Chat{Id: -100, Timezone: "Europe/Berlin", DigestTime: "12:00"},
+		}
+		assert.Equal(t, int64(-100), recipient.ChatId())
+		assert.True(t, recipient.IsDue(models.WeeklyDigestSubscription, now))
+		assert.False(t, recipient.IsDue(models.WeeklyDigestSubscription, now.AddDate(0, 0, 1)))
+	})
+
+	t.Run("chat subscriptions exclude artist alerts", func(t *testing.T) {
+		keyboard := GenerateChatSubscriptionsKeyboard(i18n.Ru, nil)
+		assert.Len(t, keyboard.InlineKeyboard, len(models.ChatSubscriptionKinds))
+
+		kind, err := ParseChatSubscriptionCallbackData("chatsub:" + string(models.HistorySubscription))
+		assert.NoError(t, err)
+		assert.Equal(t, models.HistorySubscription, kind)
+
+		_, err = ParseChatSubscriptionCallbackData("chatsub:" + string(models.ArtistAlertsSubscription))
+		assert.Error(t, err)
+		_, err = ParseChatSubscriptionCallbackData("sub:" + string(models.HistorySubscription))
+		assert.Error(t, err)
+	})
+
+	t.Run("server timezone is rejected", func(t *testing.T) {
+		_, err := ParseTimezone("Local")
+		assert.Error(t, err)
+
+		loc, err := ParseTimezone("Asia/Tomsk")
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tomsk", loc.String())
+	})
+}
+
+func TestBackgroundTaskErrors(t *testing.T) {
+	t.Run("same alert once per interval", func(t *testing.T) {
+		var alerts adminAlerts
+		now := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+		assert.True(t, alerts.allow("database is locked", now))
+		assert.False(t, alerts.allow("database is locked", now.Add(time.Minute)))
+		assert.True(t, alerts.allow("disk is full", now.Add(time.Minute)))
+		assert.True(t, alerts.allow("database is locked", now.Add(AdminAlertInterval)))
+	})
+
+	t.Run("digest is retried only when every send failed", func(t *testing.T) {
+		failed := errors.New("timeout")
+		blocked := &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}
+
+		assert.False(t, digestFailed(nil))
+		assert.False(t, digestFailed([]error{nil, failed}))
+		assert.False(t, digestFailed([]error{blocked}))
+		assert.True(t, digestFailed([]error{failed, failed}))
+	})
+
+	t.Run("locations are cached", func(t *testing.T) {
+		loc := loadLocation("Europe/Moscow")
+		assert.Equal(t, "Europe/Moscow", loc.String())
+		assert.Same(t, loc, loadLocation("Europe/Moscow"))
+		assert.Same(t, time.Local, loadLocation("Mars/Olympus"))
+	})
+}
+
+// stubDigestService отдает одни и те же релизы на любой день
+type stubDigestService struct {
+	HipHopService
+	releases    []models.Release
+	chats       map[models.SubscriptionKind][]*models.Chat
+	chatsErrs   map[models.SubscriptionKind]error
+	digestDates map[int64]string
+}
+
+func (s *stubDigestService) GetReleasesByDay(
+	int, time.Month, int, models.ReleaseType, int, int,
+) ([]models.Release, error) {
+	return s.releases, nil
+}
+
+func (s *stubDigestService) GetReleasesByPeriod(time.Time, time.Time, int, int) ([]models.Release, error) {
+	return s.releases, nil
+}
+
+func (s *stubDigestService) GetSubscribers(models.SubscriptionKind) ([]*models.User, error) {
+	return nil, sqlite.ErrUserNotFound
+}
+
+func (s *stubDigestService) GetSubscribedChats(kind models.SubscriptionKind) ([]*models.Chat, error) {
+	if err := s.chatsErrs[kind]; err != nil {
+		return nil, err
+	}
+	return s.chats[kind], nil
+}
+
+func (s *stubDigestService) AddDigestRun(models.DigestRun) error {
+	return nil
+}
+
+func (s *stubDigestService) SetChatLastDigestDate(chatId int64, date string) error {
+	s.digestDates[chatId] = date
+	return nil
+}
+
+func TestSendDigest(t *testing.T) {
+	t.Run("groups get posts without list keyboard", func(t *testing.T) {
+		api := &stubRequester{}
+		service := &stubDigestService{
+			releases:    []models.Release{{Id: 1, Artist: models.Artist{Name: "Kendrick Lamar"}, Title: "GNX"}},
+			digestDates: make(map[int64]string),
+		}
+		b := &TGBot{
+			BotAPI:  &tgbotapi.BotAPI{Self: tgbotapi.User{UserName: "hiphopgeek_bot"}},
+			Service: service,
+		}
+		b.sender, _ = newTestSender(api)
+
+		failed := b.SendDigest("2024-11-18", []*DigestRecipient{{
+			Chat: &models.Chat{Id: -100, Language: string(i18n.En)},
+			Kinds: map[models.SubscriptionKind]bool{
+				models.TodayReleasesSubscription: true,
+				models.WeeklyDigestSubscription:  true,
+			},
+		}})
+		assert.Zero(t, failed)
+		assert.Equal(t, "2024-11-18", service.digestDates[-100])
+
+		if assert.Len(t, api.sent, 2) {
+			for _, sent := range api.sent {
+				post := sent.(tgbotapi.PhotoConfig)
+				assert.Equal(t, int64(-100), post.ChatID)
+				assert.Nil(t, post.ReplyMarkup)
+				assert.Contains(t, post.Caption, "GNX")
+			}
+		}
+	})
+
+	t.Run("failed kind doesn't stop other kinds", func(t *testing.T) {
+		t.Setenv("ADMIN_ID", "42")
+		api := &stubRequester{}
+		chat := &models.Chat{Id: -100, Timezone: "UTC", DigestTime: "09:00", Language: string(i18n.En)}
+		service := &stubDigestService{
+			releases: []models.Release{{Id: 1, Artist: models.Artist{Name: "Kendrick Lamar"}, Title: "GNX"}},
+			chats: map[models.SubscriptionKind][]*models.Chat{
+				models.TodayReleasesSubscription: {chat},
+			},
+			chatsErrs: map[models.SubscriptionKind]error{
+				models.HistorySubscription: errors.New("database is locked"),
+			},
+			digestDates: make(map[int64]string),
+		}
+		b := &TGBot{
+			BotAPI:  &tgbotapi.BotAPI{Self: tgbotapi.User{UserName: "hiphopgeek_bot"}},
+			Service: service,
+		}
+		b.sender, _ = newTestSender(api)
+
+		// вторник, еженедельной рассылки нет
+		b.SendDueDigests(time.Date(2024, time.November, 19, 9, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2024-11-19", service.digestDates[-100])
+		if assert.Len(t, api.sent, 2) {
+			assert.Contains(t, api.sent[0].(tgbotapi.MessageConfig).Text, "database is locked")
+			assert.Equal(t, int64(-100), api.sent[1].(tgbotapi.PhotoConfig).ChatID)
+		}
+	})
+}
+
+type stubRemindersService struct {
+	HipHopService
+	reminders []models.Reminder
+	release   models.Release
+	attempts  map[int64]string
+	deleted   []int64
+}
+
+func (s *stubRemindersService) GetDueReminders(time.Time) ([]models.Reminder, error) {
+	return s.reminders, nil
+}
+
+func (s *stubRemindersService) GetRelease(int) (*models.Release, error) {
+	return &s.release, nil
+}
+
+func (s *stubRemindersService) GetArtistByName(name string) (*db.ArtistDB, error) {
+	return &db.ArtistDB{Id: 7, Name: name}, nil
+}
+
+func (s *stubRemindersService) GetFollowedArtists(int64) ([]*db.ArtistDB, error) {
+	return nil, sqlite.ErrArtistNotFound
+}
+
+func (s *stubRemindersService) IsReleaseSaved(int64, int) (bool, error) {
+	return false, nil
+}
+
+func (s *stubRemindersService) SetReminderAttemptDate(userId int64, _ int, date string) error {
+	s.attempts[userId] = date
+	return nil
+}
+
+func (s *stubRemindersService) DeleteReminder(userId int64, _ int) error {
+	s.deleted = append(s.deleted, userId)
+	return nil
+}
+
+func TestSendDueReminders(t *testing.T) {
+	t.Run("failed reminder is retried the next day", func(t *testing.T) {
+		api := &stubRequester{errs: []error{&tgbotapi.Error{Code: http.StatusBadRequest, Message: "Bad Request"}}}
+		service := &stubRemindersService{
+			reminders: []models.Reminder{{
+				User:      &models.User{Id: 1, Timezone: "UTC", DigestTime: "09:00"},
+				ReleaseId: 1,
+			}},
+			release: models.Release{
+				Id:      1,
+				Artist:  models.Artist{Name: "Kendrick Lamar"},
+				Title:   "GNX",
+				OutDate: types.NewCustomDate(2024, time.November, 22),
+			},
+			attempts: make(map[int64]string),
+		}
+		b := &TGBot{BotAPI: &tgbotapi.BotAPI{}, Service: service}
+		b.sender, _ = newTestSender(api)
+
+		now := time.Date(2024, time.November, 22, 9, 0, 0, 0, time.UTC)
+		b.SendDueReminders(now)
+		assert.Equal(t, 1, api.calls)
+		assert.Equal(t, "2024-11-22", service.attempts[1])
+		assert.Empty(t, service.deleted)
+
+		// минутой позже в том же окне рассылки попытки нет
+		service.reminders[0].LastAttemptDate = service.attempts[1]
+		b.SendDueReminders(now.Add(time.Minute))
+		assert.Equal(t, 1, api.calls)
+
+		b.SendDueReminders(now.AddDate(0, 0, 1))
+		assert.Equal(t, 2, api.calls)
+		assert.Equal(t, []int64{1}, service.deleted)
+	})
+}
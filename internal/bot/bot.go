@@ -2,61 +2,150 @@ package bot
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"os"
-	"strconv"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
-	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/i18n"
 	"hip-hop-geek/internal/models"
+	"hip-hop-geek/pkg/covers"
 )
 
 type HipHopService interface {
-	GetMonthReleases(year int, month time.Month, limit, offset int) []models.Release
-	GetAllYearReleases(year, limit, offset int) []models.Release
+	GetMonthReleases(
+		year int,
+		month time.Month,
+		releaseType models.ReleaseType,
+		limit, offset int,
+	) ([]models.Release, error)
+	GetAllYearReleases(year, limit, offset int) ([]models.Release, error)
 	GetAllYearSingles(year int, withCover bool) []models.Release
 	GetTodayEvents() ([]*models.TodayPost, error)
-	GetReleasesByDay(year int, month time.Month, day, limit, offset int) []models.Release
+	GetEventsByDate(date time.Time) ([]*models.TodayPost, error)
+	GetReleasesByDay(
+		year int,
+		month time.Month,
+		day int,
+		releaseType models.ReleaseType,
+		limit, offset int,
+	) ([]models.Release, error)
 	AddUser(user models.User) error
-	GetUserByUsername(username string) (*models.User, error)
-	GetAllSubscribers() ([]*models.User, error)
-	SetTodaySubscribe(userId int64, isSubscribe bool) error
-	SetUserState(userId int64, messageType, messageId int, pageCount int) error
+	GetUserById(userId int64) (*models.User, error)
+	GetUsers() ([]*models.User, error)
+	Subscribe(userId int64, kind models.SubscriptionKind) error
+	Unsubscribe(userId int64, kind models.SubscriptionKind) error
+	GetUserSubscriptions(userId int64) ([]models.SubscriptionKind, error)
+	GetSubscribers(kind models.SubscriptionKind) ([]*models.User, error)
+	SetUserTimezone(userId int64, timezone string) error
+	SetUserDigestTime(userId int64, digestTime string) error
+	SetLastDigestDate(userId int64, date string) error
+	SetUserReleaseTypeFilter(userId int64, releaseType models.ReleaseType) error
+	SetUserLanguage(userId int64, language string) error
+	SetUserActive(userId int64, isActive bool) error
+	GetRelease(id int) (*models.Release, error)
+	AddManualRelease(release models.Release) (int, error)
+	UpdateReleaseTitle(releaseId int, title string) error
+	SetReleaseArtist(releaseId int, artistName string) error
+	UpdateReleaseType(releaseId int, releaseType models.ReleaseType) error
+	UpdateReleaseDate(releaseId int, date time.Time) error
+	UpdateReleaseCoverUrl(releaseId int, coverUrl string) error
+	DeleteRelease(releaseId int) error
+	MergeArtists(fromId, toId int) error
+	GetArtistById(id int) (*db.ArtistDB, error)
+	GetArtistByName(artistName string) (*db.ArtistDB, error)
+	FindArtistByName(artistName string) (*db.ArtistDB, error)
+	FollowArtist(userId int64, artistId int) error
+	UnfollowArtist(userId int64, artistId int) error
+	GetFollowedArtists(userId int64) ([]*db.ArtistDB, error)
+	GetArtistFollowers(artistId int) ([]*models.User, error)
+	AddReminder(userId int64, releaseId int) error
+	DeleteReminder(userId int64, releaseId int) error
+	SetReminderAttemptDate(userId int64, releaseId int, date string) error
+	HasReminder(userId int64, releaseId int) (bool, error)
+	GetDueReminders(until time.Time) ([]models.Reminder, error)
+	SaveRelease(userId int64, releaseId int) error
+	UnsaveRelease(userId int64, releaseId int) error
+	IsReleaseSaved(userId int64, releaseId int) (bool, error)
+	GetSavedReleases(userId int64, byDate bool, limit, offset int) ([]models.Release, error)
+	SearchReleases(query string, limit, offset int) ([]models.Release, error)
+	GetReleaseYears() ([]int, error)
+	GetReleasesByPeriod(from, to time.Time, limit, offset int) ([]models.Release, error)
+	AddChat(chat models.Chat) error
+	GetChat(chatId int64) (*models.Chat, error)
+	DeleteChat(chatId int64) error
+	SetChatTimezone(chatId int64, timezone string) error
+	SetChatDigestTime(chatId int64, digestTime string) error
+	SetChatLastDigestDate(chatId int64, date string) error
+	SubscribeChat(chatId int64, kind models.SubscriptionKind) error
+	UnsubscribeChat(chatId int64, kind models.SubscriptionKind) error
+	GetChatSubscriptions(chatId int64) ([]models.SubscriptionKind, error)
+	GetSubscribedChats(kind models.SubscriptionKind) ([]*models.Chat, error)
+	AddPublication(publication models.Publication) error
+	IsPublished(channelId int64, kind models.SubscriptionKind, date string) (bool, error)
+	AddDigestRun(run models.DigestRun) error
+	GetStats() (*models.Stats, error)
 	Close()
 }
 
 type UpdaterInterface interface {
 	RefreshReleases(years []int)
+	RefreshMonth(year int, month time.Month)
+	CurrentYears() []int
+}
+
+type CollageMaker interface {
+	MakeCollage(urls []string) ([]byte, error)
 }
 
 type TGBot struct {
 	*tgbotapi.BotAPI
 	Service HipHopService
 	Updater UpdaterInterface
+	Collage CollageMaker
+	drafts  *broadcastDrafts
+	sender  *Sender
+	router  *Router
+	alerts  adminAlerts
 }
 
-func NewTGBot(botToken string, service HipHopService, updater UpdaterInterface) *TGBot {
+func NewTGBot(botToken string, service HipHopService, updater UpdaterInterface) (*TGBot, error) {
 	bot, err := tgbotapi.NewBotAPI(botToken)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error while connecting to telegram: %w", err)
 	}
 
-	return &TGBot{
-		bot,
-		service,
-		updater,
+	tgBot := &TGBot{
+		BotAPI:  bot,
+		Service: service,
+		Updater: updater,
+		Collage: covers.NewCollage(),
+		drafts:  newBroadcastDrafts(),
+		sender:  NewSender(bot),
 	}
+	tgBot.router = tgBot.routes()
+	return tgBot, nil
 }
 
+// Start получает обновления long polling'ом. Вебхук, оставшийся от запуска
+// в режиме вебхука, удаляется, иначе Telegram не отдаст обновления через getUpdates.
 func (b *TGBot) Start(ctx context.Context, timeout int) {
 	log.Println("bot start polling...")
+	if _, err := b.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("error while deleting webhook: %s", err)
+	}
+
 	updatesConfig := tgbotapi.NewUpdate(0)
 	updatesConfig.Timeout = timeout
 
-	updates := b.GetUpdatesChan(updatesConfig)
+	b.serve(ctx, b.GetUpdatesChan(updatesConfig))
+}
 
+// serve разбирает обновления, пришедшие через long polling или вебхук.
+func (b *TGBot) serve(ctx context.Context, updates <-chan tgbotapi.Update) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -65,136 +154,174 @@ func (b *TGBot) Start(ctx context.Context, timeout int) {
 
 		// handling all updates
 		case upd := <-updates:
-
-			chat := upd.FromChat()
-			if chat == nil {
-				log.Println("user delete bot, skip update")
-				continue
-			}
-
-			user, err := b.Service.GetUserByUsername(chat.UserName)
-			if err != nil {
-				if err == sqlite.ErrUserNotFound {
-					user = &models.User{
-						Id:               chat.ID,
-						Username:         chat.UserName,
-						IsTodaySubscribe: false,
-					}
-					err = b.Service.AddUser(*user)
-					if err != nil {
-						log.Fatal(err)
-					}
-				} else {
-					log.Fatal(err)
-				}
-			}
-
-			if upd.Message != nil {
-				log.Printf(
-					"received message update from ID %d with text %s",
-					upd.Message.From.ID,
-					upd.Message.Text,
-				)
-				go b.messageHandler(upd, user)
-
-			} else if upd.CallbackQuery != nil {
-				log.Printf(
-					"received callback update from ID %d with data %s",
-					upd.CallbackQuery.Message.From.ID,
-					upd.CallbackData(),
-				)
-				go b.callbackHandler(upd, user)
-			}
+			b.dispatch(upd)
 		}
 	}
 }
 
-func (b *TGBot) messageHandler(upd tgbotapi.Update, user *models.User) {
-	adminId, _ := strconv.ParseInt(os.Getenv("ADMIN_ID"), 10, 64)
-	deleteUserMsg := tgbotapi.NewDeleteMessage(user.Id, upd.Message.MessageID)
-	b.Send(deleteUserMsg)
-
-	if upd.Message.IsCommand() {
-		b.commandHandler(upd, user)
+func (b *TGBot) dispatch(upd tgbotapi.Update) {
+	// inline queries come without chat, they can be sent from any chat
+	if upd.InlineQuery != nil {
+		log.Printf(
+			"received inline query from ID %d with query %s",
+			upd.InlineQuery.From.ID,
+			upd.InlineQuery.Query,
+		)
+		b.handle(upd, withUpdate(b.inlineQueryHandler))
 		return
 	}
 
-	switch upd.Message.Text {
-	case TodayButtonText:
-		b.TodayEventHandler(upd.Message.Chat.ID)
-
-	case TodayReleasesButtonText:
-		chatId := upd.Message.Chat.ID
-		now := time.Now().UTC()
-		releases := b.Service.GetReleasesByDay(now.Year(), now.Month(), now.Day(), -1, 0)
-		if releases == nil {
-			b.mustSend(tgbotapi.NewMessage(chatId, "No releases today :("))
-		} else {
-			b.TodayReleasesHandler(user, releases)
-		}
-
-	case MonthReleasesButtonText:
-		b.ReleasesHandler(upd, user)
-
-	case SubscribeButtonText:
-		b.SubscribeHandler(user)
-
-	case UnsubscribeButtonText:
-		b.UnsubscribeHandler(user)
-
-	case CheckSubscribeButtonText:
-		b.CheckSubscribeHandler(user)
-
-	case RefreshReleasesButtonText:
-		if user.Id != adminId {
-			return
-		}
+	// бота добавили в группу или канал либо удалили из них
+	if upd.MyChatMember != nil {
+		b.handle(upd, withUpdate(b.MyChatMemberHandler))
+		return
+	}
 
-		b.mustSend(tgbotapi.NewMessage(int64(adminId), RefreshReleasesStartMessageText))
-		b.RefreshReleasesHandler([]int{2023, 2024})
-		b.mustSend(tgbotapi.NewMessage(int64(adminId), RefreshReleasesEndMessageText))
+	chat := upd.FromChat()
+	if chat == nil {
+		log.Println("user delete bot, skip update")
+		return
+	}
 
-	case TestButtonText:
-		if user.Id != adminId {
-			return
+	// в группах и каналах сообщения настраивают рассылку чата,
+	// а кнопки под сообщениями бота нажимают обычные пользователи
+	if !chat.IsPrivate() && upd.CallbackQuery == nil {
+		if upd.Message != nil || upd.ChannelPost != nil {
+			b.handle(upd, withUpdate(b.chatMessageHandler))
 		}
+		return
+	}
 
-		inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("test", "test"),
-			),
-		)
+	// в группах работают только кнопки настройки рассылки чата, остальные
+	// правили бы общее сообщение и списки одного пользователя видели бы все
+	if !chat.IsPrivate() && !strings.HasPrefix(upd.CallbackData(), ChatSubscriptionCallbackPrefix) {
+		b.handle(upd, withUpdate(b.groupCallbackHandler))
+		return
+	}
 
-		photoMsg := tgbotapi.NewPhoto(int64(user.Id), tgbotapi.FileURL(newReleasesPicUrl))
-		photoMsg.Caption = "Test Caption"
-		photoMsg.ReplyMarkup = inlineKeyboard
+	// личные сообщения и нажатия кнопок разбирает роутер
+	b.handle(upd, b.router.Handle)
+}
 
-		b.Send(photoMsg)
+// handle разбирает обновление в своей горутине. Паника или ошибка одного обновления
+// не останавливает бота, а уходит администратору.
+func (b *TGBot) handle(upd tgbotapi.Update, handler HandlerFunc) {
+	go func() {
+		if err := Recover(handler)(upd, nil); err != nil {
+			log.Printf("error while handling update %d: %s", upd.UpdateID, err)
+			b.notifyAdmin(T(i18n.DefaultLang, ErrorAdminMessage, err))
+		}
+	}()
+}
 
+// registerUser добавляет пользователя и подписывает его на уведомления об исполнителях.
+// Без записи в users нельзя сохранять отслеживания и списки, поэтому ошибка возвращается.
+func (b *TGBot) registerUser(user *models.User) error {
+	err := b.Service.AddUser(*user)
+	if err != nil {
+		return fmt.Errorf("error while adding user %d: %w", user.Id, err)
+	}
+	// уведомления о релизах отслеживаемых исполнителей включены по умолчанию
+	err = b.Service.Subscribe(user.Id, models.ArtistAlertsSubscription)
+	if err != nil {
+		log.Printf("error while subscribing new user to artist alerts: %s", err)
 	}
 
-	log.Println("handled update")
+	return nil
 }
 
-func (b *TGBot) commandHandler(upd tgbotapi.Update, user *models.User) {
-	switch upd.Message.Command() {
-	case StartCommandText:
-		b.StartCommandHandler(upd, user)
-	}
+// routes - маршруты обновлений из личных чатов. Общие middleware выполняются по порядку:
+// паника не останавливает бота, ошибки обработчиков видит пользователь, загруженный
+// до удаления его сообщения.
+func (b *TGBot) routes() *Router {
+	r := NewRouter()
+	r.Use(Recover, LogUpdate, TimeUpdate, b.LoadUser, b.ReportErrors, b.CleanupMessage)
+	admin := b.AdminOnly
+
+	r.Command(StartCommandText, b.StartCommandHandler)
+	r.Command(FollowCommandText, b.FollowCommandHandler)
+	r.Command(UnfollowCommandText, b.UnfollowCommandHandler)
+	r.Command(FollowingCommandText, withUser(b.FollowingCommandHandler))
+	r.Command(SearchCommandText, b.SearchCommandHandler)
+	r.Command(MyListCommandText, withUser(b.MyListHandler))
+	r.Command(TimezoneCommandText, b.TimezoneCommandHandler)
+	r.Command(DigestTimeCommandText, b.DigestTimeCommandHandler)
+	r.Command(LanguageCommandText, withUser(b.LanguageHandler))
+
+	// команды администратора
+	r.Command(StatsCommandText, withUser(b.StatsCommandHandler), admin)
+	r.Command(BroadcastCommandText, withUser(b.BroadcastCommandHandler), admin)
+	r.Command(CancelCommandText, withUser(b.CancelCommandHandler), admin)
+	r.Command(AddReleaseCommandText, b.AddReleaseCommandHandler, admin)
+	r.Command(EditReleaseCommandText, b.EditReleaseCommandHandler, admin)
+	r.Command(DeleteReleaseCommandText, b.DeleteReleaseCommandHandler, admin)
+	r.Command(MergeArtistsCommandText, b.MergeArtistsCommandHandler, admin)
+	r.Command(RefreshCommandText, b.RefreshCommandHandler, admin)
+
+	// черновик рассылки - любое сообщение администратора после /broadcast
+	r.State(b.drafts.isWaiting, b.BroadcastDraftHandler, admin)
+
+	r.Button(TodayButtonText, withUser(b.TodayButtonHandler))
+	r.Button(TodayReleasesButtonText, withUser(b.TodayReleasesButtonHandler))
+	r.Button(MonthReleasesButtonText, b.ReleasesHandler)
+	r.Button(YearReleasesByMonthButtonText, withUser(b.YearReleasesHandler))
+	r.Button(UpcomingReleasesButtonText, withUser(b.UpcomingReleasesHandler))
+	r.Button(SubscriptionsButtonText, withUser(b.SubscriptionsHandler))
+	r.Button(LanguageButtonText, withUser(b.LanguageHandler))
+	r.Button(RefreshReleasesButtonText, withUser(func(user *models.User) error {
+		b.RefreshReleasesHandler(user, b.Updater.CurrentYears())
+		return nil
+	}), admin)
+	r.Button(BroadcastButtonText, withUser(b.BroadcastCommandHandler), admin)
+	r.Button(TestButtonText, withUser(b.TestButtonHandler), admin)
+
+	r.Callback(PageCountCallbackText, func(upd tgbotapi.Update, _ *models.User) error {
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	})
+	r.Callback(FollowArtistCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.FollowArtistCallbackHandler(upd, user, true)
+	})
+	r.Callback(UnfollowArtistCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.FollowArtistCallbackHandler(upd, user, false)
+	})
+	r.Callback(RemindCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.ReminderCallbackHandler(upd, user, true)
+	})
+	r.Callback(UnremindCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.ReminderCallbackHandler(upd, user, false)
+	})
+	r.Callback(SaveCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.SaveReleaseCallbackHandler(upd, user, true)
+	})
+	r.Callback(UnsaveCallbackPrefix, func(upd tgbotapi.Update, user *models.User) error {
+		return b.SaveReleaseCallbackHandler(upd, user, false)
+	})
+	r.Callback(ListCallbackPrefix, b.ListCallbackHandler)
+	r.Callback(CalendarCallbackPrefix, b.CalendarCallbackHandler)
+	r.Callback(SubscriptionCallbackPrefix, b.SubscriptionCallbackHandler)
+	r.Callback(ReleaseCallbackPrefix, b.ReleaseCardCallbackHandler)
+	r.Callback(LanguageCallbackPrefix, b.LanguageCallbackHandler)
+	r.Callback(ChatSubscriptionCallbackPrefix, b.ChatSubscriptionCallbackHandler)
+	r.Callback(BroadcastCallbackPrefix, b.BroadcastCallbackHandler, admin)
+
+	r.Unknown(func(upd tgbotapi.Update, user *models.User) error {
+		// кнопки старых форматов, например пагинация до перехода на ListView
+		if upd.CallbackQuery != nil {
+			b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(UserLang(user), OutdatedButtonMessage)))
+		}
+		return nil
+	})
+
+	return r
 }
 
-func (b *TGBot) callbackHandler(upd tgbotapi.Update, user *models.User) {
-	switch upd.CallbackData() {
-	case PreviousReleasesCallbackText:
-		b.PreviousReleasesCallbackHandler(upd, user, models.ReleasesMessage)
-	case NextReleasesCallbackText:
-		b.NextReleasesCallbackHandler(upd, user, models.ReleasesMessage)
-	case NextTodayReleasesCallbackText:
-		b.NextReleasesCallbackHandler(upd, user, models.TodayReleasesMessage)
-	case PreviousTodayReleasesCallbackText:
-		b.PreviousReleasesCallbackHandler(upd, user, models.TodayReleasesMessage)
-	case PageCountCallbackText:
-		callback := tgbotapi.NewCallback(upd.CallbackQuery.ID, "")
-		b.Send(callback)
+// updateLang - язык отправителя апдейта по language_code из Telegram.
+func updateLang(upd tgbotapi.Update) i18n.Lang {
+	from := upd.SentFrom()
+	if from == nil {
+		return i18n.DefaultLang
 	}
+
+	return i18n.FromCode(from.LanguageCode)
 }
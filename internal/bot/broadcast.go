@@ -0,0 +1,324 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// BroadcastSegment - кому отправляется рассылка: всем, подписчикам
+// или пользователям с определенным языком, тогда сегмент - код языка.
+type BroadcastSegment string
+
+const (
+	AllUsersSegment    BroadcastSegment = "all"
+	SubscribersSegment BroadcastSegment = "subs"
+)
+
+func BroadcastSegments() []BroadcastSegment {
+	segments := []BroadcastSegment{AllUsersSegment, SubscribersSegment}
+	for _, lang := range i18n.Supported {
+		segments = append(segments, BroadcastSegment(lang))
+	}
+	return segments
+}
+
+func BroadcastSegmentName(lang i18n.Lang, segment BroadcastSegment) string {
+	switch segment {
+	case AllUsersSegment:
+		return T(lang, BroadcastAllButtonText)
+	case SubscribersSegment:
+		return T(lang, BroadcastSubscribersButtonText)
+	}
+	return LanguageNames[i18n.Lang(segment)]
+}
+
+// Действия с черновиком рассылки в callback data
+const (
+	BroadcastSelectAction  = "s"
+	BroadcastConfirmAction = "ok"
+	BroadcastCancelAction  = "no"
+)
+
+// BroadcastReport - итог рассылки для администратора.
+type BroadcastReport struct {
+	Delivered int
+	Blocked   int
+	Failed    int
+}
+
+// broadcastDrafts - администраторы, от которых бот ждет сообщение для рассылки,
+// и превью, рассылка которых уже запущена.
+type broadcastDrafts struct {
+	mu      sync.Mutex
+	waiting map[int64]bool
+	sent    map[int]bool
+}
+
+func newBroadcastDrafts() *broadcastDrafts {
+	return &broadcastDrafts{waiting: make(map[int64]bool), sent: make(map[int]bool)}
+}
+
+func (d *broadcastDrafts) wait(userId int64, waiting bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.waiting[userId] = waiting
+}
+
+func (d *broadcastDrafts) isWaiting(userId int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.waiting[userId]
+}
+
+// take возвращает true, если от пользователя ждали черновик, и перестает ждать.
+func (d *broadcastDrafts) take(userId int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	waiting := d.waiting[userId]
+	delete(d.waiting, userId)
+	return waiting
+}
+
+// consume возвращает true только при первом вызове для превью,
+// повторное нажатие "Отправить" не запускает рассылку второй раз.
+func (d *broadcastDrafts) consume(previewId int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sent[previewId] {
+		return false
+	}
+	d.sent[previewId] = true
+	return true
+}
+
+func GenerateBroadcastCallbackData(action string, previewId int, segment BroadcastSegment) string {
+	return fmt.Sprintf("%s%s:%d:%s", BroadcastCallbackPrefix, action, previewId, segment)
+}
+
+func ParseBroadcastCallbackData(data string) (string, int, BroadcastSegment, error) {
+	parts := strings.Split(strings.TrimPrefix(data, BroadcastCallbackPrefix), ":")
+	if len(parts) != 3 {
+		return "", 0, "", fmt.Errorf("invalid broadcast callback data %s", data)
+	}
+
+	previewId, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, "", fmt.Errorf("invalid broadcast preview id in %s: %w", data, err)
+	}
+
+	action, segment := parts[0], BroadcastSegment(parts[2])
+	if !slices.Contains(
+		[]string{BroadcastSelectAction, BroadcastConfirmAction, BroadcastCancelAction},
+		action,
+	) {
+		return "", 0, "", fmt.Errorf("invalid broadcast action in %s", data)
+	}
+	if action != BroadcastCancelAction && !slices.Contains(BroadcastSegments(), segment) {
+		return "", 0, "", fmt.Errorf("invalid broadcast segment in %s", data)
+	}
+
+	return action, previewId, segment, nil
+}
+
+func GenerateBroadcastSegmentsKeyboard(lang i18n.Lang, previewId int) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 3)
+	segments := BroadcastSegments()
+	for i := 0; i < len(segments); i += 2 {
+		row := make([]tgbotapi.InlineKeyboardButton, 0, 2)
+		for _, segment := range segments[i:min(i+2, len(segments))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				BroadcastSegmentName(lang, segment),
+				GenerateBroadcastCallbackData(BroadcastSelectAction, previewId, segment),
+			))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, BroadcastCancelButtonText),
+			GenerateBroadcastCallbackData(BroadcastCancelAction, previewId, ""),
+		),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func GenerateBroadcastConfirmKeyboard(
+	lang i18n.Lang,
+	previewId int,
+	segment BroadcastSegment,
+) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, BroadcastConfirmButtonText),
+			GenerateBroadcastCallbackData(BroadcastConfirmAction, previewId, segment),
+		),
+		tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, BroadcastCancelButtonText),
+			GenerateBroadcastCallbackData(BroadcastCancelAction, previewId, ""),
+		),
+	))
+}
+
+// BroadcastCommandHandler просит администратора прислать сообщение для рассылки.
+func (b *TGBot) BroadcastCommandHandler(user *models.User) error {
+	b.drafts.wait(user.Id, true)
+	b.trySend(tgbotapi.NewMessage(user.Id, T(UserLang(user), BroadcastComposeMessage)))
+
+	return nil
+}
+
+func (b *TGBot) CancelCommandHandler(user *models.User) error {
+	if b.drafts.take(user.Id) {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(UserLang(user), BroadcastCanceledMessage)))
+	}
+
+	return nil
+}
+
+// BroadcastDraftHandler показывает администратору, как сообщение увидят пользователи.
+// Рассылается копия превью, поэтому исходное сообщение можно удалить.
+func (b *TGBot) BroadcastDraftHandler(upd tgbotapi.Update, user *models.User) error {
+	// /cancel мог прийти раньше
+	if !b.drafts.take(user.Id) {
+		return nil
+	}
+
+	lang := UserLang(user)
+	preview, err := b.CopyMessage(
+		tgbotapi.NewCopyMessage(user.Id, upd.Message.Chat.ID, upd.Message.MessageID),
+	)
+	if err != nil {
+		return fmt.Errorf("error while copying broadcast draft: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(user.Id, T(lang, BroadcastChooseSegmentMessage))
+	msg.ReplyMarkup = GenerateBroadcastSegmentsKeyboard(lang, preview.MessageID)
+	b.trySend(msg)
+
+	return nil
+}
+
+func (b *TGBot) BroadcastCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	msg := upd.CallbackQuery.Message
+	action, previewId, segment, err := ParseBroadcastCallbackData(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while parsing broadcast callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, OutdatedButtonMessage)))
+		return nil
+	}
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+
+	switch action {
+	case BroadcastCancelAction:
+		b.Send(tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, T(lang, BroadcastCanceledMessage)))
+
+	case BroadcastSelectAction:
+		recipients, err := b.BroadcastRecipients(segment)
+		if err != nil {
+			return fmt.Errorf("error while getting broadcast recipients: %w", err)
+		}
+
+		text := T(lang, BroadcastConfirmMessage, len(recipients), BroadcastSegmentName(lang, segment))
+		keyboard := GenerateBroadcastConfirmKeyboard(lang, previewId, segment)
+		if len(recipients) == 0 {
+			text = T(lang, BroadcastNoRecipientsMessage)
+			keyboard = GenerateBroadcastSegmentsKeyboard(lang, previewId)
+		}
+		b.Send(tgbotapi.NewEditMessageTextAndMarkup(msg.Chat.ID, msg.MessageID, text, keyboard))
+
+	case BroadcastConfirmAction:
+		if !b.drafts.consume(previewId) {
+			return nil
+		}
+		// без клавиатуры подтверждение нельзя нажать еще раз
+		b.Send(tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, T(lang, BroadcastStartedMessage)))
+
+		recipients, err := b.BroadcastRecipients(segment)
+		if err != nil {
+			return fmt.Errorf("error while getting broadcast recipients: %w", err)
+		}
+
+		log.Printf("broadcasting message %d to %d users of segment %s", previewId, len(recipients), segment)
+		report := b.Broadcast(user.Id, previewId, recipients)
+		log.Printf("broadcast finished: %+v", report)
+
+		b.trySend(tgbotapi.NewMessage(
+			user.Id,
+			T(lang, BroadcastReportMessage, report.Delivered, report.Blocked, report.Failed),
+		))
+	}
+
+	return nil
+}
+
+// BroadcastRecipients возвращает пользователей сегмента, у каждого по одному разу.
+// Подписчики - те, кто получает рассылку по расписанию: уведомления об исполнителях
+// включены у всех пользователей и подписчиком не делают.
+func (b *TGBot) BroadcastRecipients(segment BroadcastSegment) ([]*models.User, error) {
+	if segment == SubscribersSegment {
+		var recipients []*models.User
+		added := make(map[int64]bool)
+		for _, kind := range DigestKinds {
+			subscribers, err := b.Service.GetSubscribers(kind)
+			if err != nil && !errors.Is(err, sqlite.ErrUserNotFound) {
+				return nil, err
+			}
+			for _, subscriber := range subscribers {
+				if !added[subscriber.Id] {
+					added[subscriber.Id] = true
+					recipients = append(recipients, subscriber)
+				}
+			}
+		}
+		return recipients, nil
+	}
+
+	users, err := b.Service.GetUsers()
+	if err != nil {
+		if errors.Is(err, sqlite.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if segment == AllUsersSegment {
+		return users, nil
+	}
+
+	return slices.DeleteFunc(users, func(user *models.User) bool {
+		return UserLang(user) != i18n.Lang(segment)
+	}), nil
+}
+
+// Broadcast копирует сообщение messageId из чата fromChatId всем получателям.
+// Скорость отправки и повторы после 429 обеспечивает очередь Sender.
+func (b *TGBot) Broadcast(fromChatId int64, messageId int, recipients []*models.User) BroadcastReport {
+	var report BroadcastReport
+	for _, recipient := range recipients {
+		_, err := b.CopyMessage(tgbotapi.NewCopyMessage(recipient.Id, fromChatId, messageId))
+
+		switch Outcome(err) {
+		case Delivered:
+			report.Delivered++
+		case Blocked:
+			report.Blocked++
+		default:
+			log.Printf("error while broadcasting to user %d: %s", recipient.Id, err)
+			report.Failed++
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+func TestBroadcastCallbackData(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		data := GenerateBroadcastCallbackData(BroadcastConfirmAction, 1234, BroadcastSegment(i18n.En))
+		assert.Equal(t, "bc:ok:1234:en", data)
+
+		action, previewId, segment, err := ParseBroadcastCallbackData(data)
+		assert.NoError(t, err)
+		assert.Equal(t, BroadcastConfirmAction, action)
+		assert.Equal(t, 1234, previewId)
+		assert.Equal(t, BroadcastSegment(i18n.En), segment)
+	})
+
+	t.Run("cancel has no segment", func(t *testing.T) {
+		action, _, _, err := ParseBroadcastCallbackData(
+			GenerateBroadcastCallbackData(BroadcastCancelAction, 1234, ""),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, BroadcastCancelAction, action)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		for _, data := range []string{"bc:ok:1234", "bc:ok:abc:all", "bc:go:1234:all", "bc:s:1234:de"} {
+			_, _, _, err := ParseBroadcastCallbackData(data)
+			assert.Error(t, err, data)
+		}
+	})
+
+	t.Run("keyboard has every segment", func(t *testing.T) {
+		keyboard := GenerateBroadcastSegmentsKeyboard(i18n.Ru, 1234)
+		buttons := 0
+		for _, row := range keyboard.InlineKeyboard {
+			buttons += len(row)
+		}
+		assert.Equal(t, len(BroadcastSegments())+1, buttons)
+	})
+
+	t.Run("preview is sent once", func(t *testing.T) {
+		drafts := newBroadcastDrafts()
+		assert.True(t, drafts.consume(1234))
+		assert.False(t, drafts.consume(1234))
+		assert.True(t, drafts.consume(1235))
+	})
+}
+
+// stubSubscribersService хранит подписки пользователей в памяти
+type stubSubscribersService struct {
+	HipHopService
+	subscriptions map[models.SubscriptionKind][]*models.User
+}
+
+func (s *stubSubscribersService) GetSubscribers(kind models.SubscriptionKind) ([]*models.User, error) {
+	if len(s.subscriptions[kind]) == 0 {
+		return nil, sqlite.ErrUserNotFound
+	}
+	return s.subscriptions[kind], nil
+}
+
+func TestBroadcastRecipients(t *testing.T) {
+	digest := &models.User{Id: 1, Username: "digest"}
+	alertsOnly := &models.User{Id: 2, Username: "alerts"}
+	b := &TGBot{Service: &stubSubscribersService{
+		subscriptions: map[models.SubscriptionKind][]*models.User{
+			models.HistorySubscription:       {digest},
+			models.TodayReleasesSubscription: {digest},
+			models.ArtistAlertsSubscription:  {digest, alertsOnly},
+		},
+	}}
+
+	recipients, err := b.BroadcastRecipients(SubscribersSegment)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.User{digest}, recipients)
+}
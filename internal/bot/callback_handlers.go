@@ -1,128 +1,383 @@
 package bot
 
 import (
+	"errors"
+	"fmt"
 	"log"
-	"time"
+	"slices"
+	"strconv"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/db/sqlite"
 	"hip-hop-geek/internal/models"
 )
 
-func (b *TGBot) PreviousReleasesCallbackHandler(
+// ListCallbackHandler показывает страницу списка релизов, закодированную в кнопке.
+// Смена фильтра запоминается, чтобы следующие списки открывались с ним же.
+func (b *TGBot) ListCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	view, err := DecodeListView(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while decoding list callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, OutdatedButtonMessage)))
+		return nil
+	}
+
+	if view.HasTypeFilter() && view.Filter != user.ReleaseTypeFilter {
+		if err := b.Service.SetUserReleaseTypeFilter(user.Id, view.Filter); err != nil {
+			log.Printf("error while saving release type filter: %s", err)
+		}
+	}
+
+	releases, err := b.GetListReleases(UserNow(user), user.Id, view)
+	if err != nil {
+		return fmt.Errorf("error while getting releases for list %s: %w", upd.CallbackData(), err)
+	}
+
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+	if err := b.EditListPage(upd.CallbackQuery.Message, lang, view, releases); err != nil {
+		log.Printf("error while editing list message for user %d: %s", user.Id, err)
+	}
+
+	return nil
+}
+
+func (b *TGBot) FollowArtistCallbackHandler(
 	upd tgbotapi.Update,
 	user *models.User,
-	messageType models.MessageIdType,
-) {
-	now := time.Now().UTC()
-	chatId := upd.CallbackQuery.From.ID
-	// pageCount := user.ReleasesPageCount
+	follow bool,
+) error {
+	lang := UserLang(user)
+	prefix := UnfollowArtistCallbackPrefix
+	if follow {
+		prefix = FollowArtistCallbackPrefix
+	}
+	artistId, err := strconv.Atoi(strings.TrimPrefix(upd.CallbackData(), prefix))
+	if err != nil {
+		log.Printf("error while parsing artist id from callback %s: %s", upd.CallbackData(), err)
+		return nil
+	}
+
+	artist, err := b.Service.GetArtistById(artistId)
+	if err != nil {
+		return fmt.Errorf("error while getting artist for callback: %w", err)
+	}
+
+	answer := T(lang, SuccessUnfollowMessage, artist.Name)
+	if follow {
+		answer = T(lang, SuccessFollowMessage, artist.Name)
+		err = b.Service.FollowArtist(user.Id, artist.Id)
+		if errors.Is(err, sqlite.ErrAlreadyFollowing) {
+			answer = T(lang, AlreadyFollowingMessage, artist.Name)
+			err = nil
+		}
+	} else {
+		err = b.Service.UnfollowArtist(user.Id, artist.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("error while changing follow on artist %s: %w", artist.Name, err)
+	}
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, answer))
+
+	msg := upd.CallbackQuery.Message
+	// на карточке релиза просто меняем кнопку, а список отслеживаемых перерисовываем
+	if msg.Photo != nil {
+		keyboard := ReplaceFollowButton(lang, msg.ReplyMarkup, artist, follow)
+		b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
+		return nil
+	}
+
+	artists, err := b.Service.GetFollowedArtists(user.Id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrArtistNotFound) {
+			b.Send(tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, T(lang, NoFollowedArtistsMessage)))
+			return nil
+		}
+		log.Printf("error while getting followed artists: %s", err)
+		return nil
+	}
+	keyboard := GenerateFollowedArtistsKeyboard(lang, artists)
+	b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
 
-	var releases []models.Release
-	var pageCount int
+	return nil
+}
 
-	switch messageType {
-	case models.ReleasesMessage:
-		pageCount = user.ReleasesPageCount
-	case models.TodayReleasesMessage:
-		pageCount = user.TodayReleasesPageCount
+// CalendarCallbackHandler переключает выбор года и месяца,
+// сам месяц открывается уже как список релизов.
+func (b *TGBot) CalendarCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	year, err := ParseCalendarCallbackData(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while parsing calendar callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, OutdatedButtonMessage)))
+		return nil
 	}
-	var msgId int64
 
-	if pageCount > 1 {
-		pageCount -= 1
+	media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(newReleasesPicUrl))
+	var inlineKeyboard tgbotapi.InlineKeyboardMarkup
+
+	if year == 0 {
+		years, err := b.Service.GetReleaseYears()
+		if err != nil {
+			log.Printf("error while getting release years: %s", err)
+			b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, ReleasesNotFoundMessage)))
+			return nil
+		}
+		media.Caption = T(lang, ChooseYearMessage)
+		inlineKeyboard = GenerateYearsKeyboard(years)
 	} else {
-		log.Fatalf("cant get previous page - its 1")
-	}
-
-	offset := (pageCount - 1) * StandardReleasesLimit
-	switch messageType {
-	case models.ReleasesMessage:
-		releases = b.Service.GetMonthReleases(
-			now.Year(), now.Month(),
-			StandardReleasesLimit,
-			offset,
-		)
-		msgId = user.ReleasesMessageId
-	case models.TodayReleasesMessage:
-		releases = b.Service.GetReleasesByDay(
-			now.Year(), now.Month(), now.Day(),
-			StandardReleasesLimit,
-			offset,
-		)
-		msgId = user.TodayReleasesMessageId
-	}
-
-	msg := GenerateReleasesEditMessage(releases)
-	inlineKeyboard := GenerateInlineReleasesKeyboard(messageType, pageCount, releases)
+		media.Caption = T(lang, ChooseMonthMessage, year)
+		inlineKeyboard = GenerateYearByMonthKeyboard(lang, year, user.ReleaseTypeFilter)
+	}
+
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
 	msgEdit := tgbotapi.EditMessageMediaConfig{
 		BaseEdit: tgbotapi.BaseEdit{
-			ChatID:      chatId,
-			MessageID:   int(msgId),
+			ChatID:      upd.CallbackQuery.Message.Chat.ID,
+			MessageID:   upd.CallbackQuery.Message.MessageID,
 			ReplyMarkup: &inlineKeyboard,
 		},
-		Media: msg,
+		Media: media,
+	}
+
+	if _, err := b.Send(msgEdit); err != nil {
+		log.Printf("error while editing calendar message for user %d: %s", user.Id, err)
+	}
+
+	return nil
+}
+
+func (b *TGBot) SubscriptionCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	kind, err := ParseSubscriptionCallbackData(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while parsing subscription callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	kinds, err := b.Service.GetUserSubscriptions(user.Id)
+	if err != nil {
+		return fmt.Errorf("error while getting user subscriptions: %w", err)
 	}
 
-	doneMsg, err := b.Send(msgEdit)
+	answer := T(lang, SubscribedMessage, T(lang, SubscriptionKindsText[kind]))
+	if slices.Contains(kinds, kind) {
+		answer = T(lang, UnsubscribedMessage, T(lang, SubscriptionKindsText[kind]))
+		err = b.Service.Unsubscribe(user.Id, kind)
+		kinds = slices.DeleteFunc(kinds, func(k models.SubscriptionKind) bool { return k == kind })
+	} else {
+		err = b.Service.Subscribe(user.Id, kind)
+		kinds = append(kinds, kind)
+	}
 	if err != nil {
-		log.Fatalf("error while sending photo message: %s", err)
+		return fmt.Errorf("error while toggling %s subscription: %w", kind, err)
 	}
-	b.Service.SetUserState(user.Id, int(messageType), doneMsg.MessageID, pageCount)
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, answer))
+
+	msg := upd.CallbackQuery.Message
+	keyboard := GenerateSubscriptionsKeyboard(lang, kinds)
+	b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
+
+	return nil
 }
 
-func (b *TGBot) NextReleasesCallbackHandler(
-	upd tgbotapi.Update,
-	user *models.User,
-	messageType models.MessageIdType,
-) {
-	now := time.Now().UTC()
-	chatId := upd.CallbackQuery.From.ID
-	var releases []models.Release
-	var msgId int64
-	var pageCount int
-
-	switch messageType {
-	case models.ReleasesMessage:
-		pageCount = user.ReleasesPageCount
-		msgId = user.ReleasesMessageId
-	case models.TodayReleasesMessage:
-		pageCount = user.TodayReleasesPageCount
-		msgId = user.TodayReleasesMessageId
-	}
-
-	pageCount = pageCount + 1
-	offset := (pageCount - 1) * StandardReleasesLimit
-
-	switch messageType {
-	case models.ReleasesMessage:
-		releases = b.Service.GetMonthReleases(
-			now.Year(), now.Month(),
-			StandardReleasesLimit,
-			offset,
-		)
-	case models.TodayReleasesMessage:
-		releases = b.Service.GetReleasesByDay(
-			now.Year(), now.Month(), now.Day(),
-			StandardReleasesLimit,
-			offset,
-		)
-	}
-
-	msg := GenerateReleasesEditMessage(releases)
-	inlineKeyboard := GenerateInlineReleasesKeyboard(messageType, pageCount, releases)
+func (b *TGBot) ReleaseCardCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	releaseId, backData, err := ParseReleaseCallbackData(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while parsing release callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	release, err := b.Service.GetRelease(releaseId)
+	if err != nil {
+		log.Printf("error while getting release %d for card: %s", releaseId, err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, ReleasesNotFoundMessage)))
+		return nil
+	}
+
+	artist, err := b.Service.GetArtistByName(release.Artist.Name)
+	if err != nil {
+		return fmt.Errorf("error while getting artist of release %d: %w", release.Id, err)
+	}
+
+	state := ReleaseCardState{
+		CanRemind: IsUpcomingRelease(*release, UserNow(user)),
+		BackData:  backData,
+	}
+	state.IsFollowing, err = b.isFollowing(user, artist.Id)
+	if err != nil {
+		log.Printf("error while getting followed artists: %s", err)
+	}
+	state.IsSaved, err = b.Service.IsReleaseSaved(user.Id, release.Id)
+	if err != nil {
+		log.Printf("error while checking saved release %d: %s", release.Id, err)
+	}
+	if state.CanRemind {
+		state.HasReminder, err = b.Service.HasReminder(user.Id, release.Id)
+		if err != nil {
+			log.Printf("error while checking reminder on release %d: %s", release.Id, err)
+		}
+	}
+
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+	inlineKeyboard := GenerateReleaseCardKeyboard(lang, *release, artist, state)
+	media := GenerateReleaseCardMessage(lang, *release)
+	// администратору нужен id, чтобы исправить релиз через /editrelease
+	if IsAdmin(user.Id) {
+		media.Caption += fmt.Sprintf(ReleaseIdText, release.Id)
+	}
 	msgEdit := tgbotapi.EditMessageMediaConfig{
 		BaseEdit: tgbotapi.BaseEdit{
-			ChatID:      chatId,
-			MessageID:   int(msgId),
+			ChatID:      upd.CallbackQuery.Message.Chat.ID,
+			MessageID:   upd.CallbackQuery.Message.MessageID,
 			ReplyMarkup: &inlineKeyboard,
 		},
-		Media: msg,
+		Media: media,
+	}
+
+	if _, err := b.Send(msgEdit); err != nil {
+		log.Printf("error while showing release card for user %d: %s", user.Id, err)
+	}
+
+	return nil
+}
+
+// ReminderCallbackHandler ставит или снимает напоминание о выходе релиза с его карточки.
+func (b *TGBot) ReminderCallbackHandler(
+	upd tgbotapi.Update,
+	user *models.User,
+	remind bool,
+) error {
+	lang := UserLang(user)
+	prefix := UnremindCallbackPrefix
+	if remind {
+		prefix = RemindCallbackPrefix
+	}
+	releaseId, err := strconv.Atoi(strings.TrimPrefix(upd.CallbackData(), prefix))
+	if err != nil {
+		log.Printf("error while parsing release id from callback %s: %s", upd.CallbackData(), err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	release, err := b.Service.GetRelease(releaseId)
+	if err != nil {
+		log.Printf("error while getting release %d for reminder: %s", releaseId, err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, ReleasesNotFoundMessage)))
+		return nil
+	}
+
+	answer := T(lang, ReminderDeletedMessage)
+	if remind {
+		if !IsUpcomingRelease(*release, UserNow(user)) {
+			b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, ReleaseAlreadyOutMessage)))
+			return nil
+		}
+		answer = T(lang, ReminderSetMessage)
+		err = b.Service.AddReminder(user.Id, release.Id)
+	} else {
+		err = b.Service.DeleteReminder(user.Id, release.Id)
+	}
+	if err != nil {
+		return fmt.Errorf("error while changing reminder on release %d: %w", release.Id, err)
 	}
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, answer))
 
-	doneMsg, err := b.Send(msgEdit)
+	msg := upd.CallbackQuery.Message
+	keyboard := ReplaceReminderButton(lang, msg.ReplyMarkup, release.Id, remind)
+	b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
+
+	return nil
+}
+
+// SaveReleaseCallbackHandler добавляет релиз в личный список /mylist или убирает из него.
+func (b *TGBot) SaveReleaseCallbackHandler(
+	upd tgbotapi.Update,
+	user *models.User,
+	save bool,
+) error {
+	lang := UserLang(user)
+	prefix := UnsaveCallbackPrefix
+	if save {
+		prefix = SaveCallbackPrefix
+	}
+	releaseId, err := strconv.Atoi(strings.TrimPrefix(upd.CallbackData(), prefix))
+	if err != nil {
+		log.Printf("error while parsing release id from callback %s: %s", upd.CallbackData(), err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	answer := T(lang, ReleaseUnsavedMessage)
+	if save {
+		// релиз могли удалить, пока карточка висела в чате
+		if _, err := b.Service.GetRelease(releaseId); err != nil {
+			log.Printf("error while getting release %d for saving: %s", releaseId, err)
+			b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(lang, ReleasesNotFoundMessage)))
+			return nil
+		}
+		answer = T(lang, ReleaseSavedMessage)
+		err = b.Service.SaveRelease(user.Id, releaseId)
+	} else {
+		err = b.Service.UnsaveRelease(user.Id, releaseId)
+	}
+	if err != nil {
+		return fmt.Errorf("error while changing saved release %d: %w", releaseId, err)
+	}
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, answer))
+
+	msg := upd.CallbackQuery.Message
+	keyboard := ReplaceSaveButton(lang, msg.ReplyMarkup, releaseId, save)
+	b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
+
+	return nil
+}
+
+func (b *TGBot) isFollowing(user *models.User, artistId int) (bool, error) {
+	artists, err := b.Service.GetFollowedArtists(user.Id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrArtistNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return slices.ContainsFunc(artists, func(artist *db.ArtistDB) bool {
+		return artist.Id == artistId
+	}), nil
+}
+
+// LanguageCallbackHandler меняет язык и присылает клавиатуру с кнопками на новом языке.
+func (b *TGBot) LanguageCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	lang, err := ParseLanguageCallbackData(upd.CallbackData())
 	if err != nil {
-		log.Fatalf("error while sending photo message: %s", err)
+		log.Printf("error while parsing language callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	if err := b.Service.SetUserLanguage(user.Id, string(lang)); err != nil {
+		return fmt.Errorf("error while saving language of user %d: %w", user.Id, err)
 	}
-	b.Service.SetUserState(user.Id, int(messageType), doneMsg.MessageID, pageCount)
+	user.Language = string(lang)
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+
+	msg := upd.CallbackQuery.Message
+	b.Send(tgbotapi.NewDeleteMessage(msg.Chat.ID, msg.MessageID))
+
+	keyboard := MainKeyboard(lang)
+	if IsAdmin(user.Id) {
+		keyboard = AdminKeyboard(lang)
+	}
+	answer := tgbotapi.NewMessage(msg.Chat.ID, T(lang, SuccessLanguageMessage))
+	answer.ReplyMarkup = keyboard
+	b.trySend(answer)
+
+	return nil
 }
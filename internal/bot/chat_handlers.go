@@ -0,0 +1,280 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// MyChatMemberHandler запоминает группы и каналы, куда добавили бота,
+// и удаляет их настройки, когда бота исключают. В личном чате такое
+// обновление приходит, когда пользователь блокирует или разблокирует бота.
+func (b *TGBot) MyChatMemberHandler(upd tgbotapi.Update) {
+	update := upd.MyChatMember
+	chat := update.Chat
+	member := update.NewChatMember
+	if chat.IsPrivate() {
+		isActive := !member.WasKicked()
+		log.Printf("user %d changed bot status to %s", chat.ID, member.Status)
+		if err := b.Service.SetUserActive(chat.ID, isActive); err != nil {
+			log.Printf("error while setting user %d active to %t: %s", chat.ID, isActive, err)
+		}
+		return
+	}
+
+	if member.HasLeft() || member.WasKicked() {
+		log.Printf("bot removed from chat %d", chat.ID)
+		if err := b.Service.DeleteChat(chat.ID); err != nil {
+			log.Printf("error while deleting chat %d: %s", chat.ID, err)
+		}
+		return
+	}
+
+	log.Printf("bot added to %s %d by user %d", chat.Type, chat.ID, update.From.ID)
+	lang := i18n.FromCode(update.From.LanguageCode)
+	_, err := b.getOrAddChat(&chat, lang)
+	if err != nil {
+		log.Printf("error while adding chat %d: %s", chat.ID, err)
+		return
+	}
+
+	// в канал приветствие ушло бы всем подписчикам
+	old := update.OldChatMember
+	if !chat.IsChannel() && (old.HasLeft() || old.WasKicked()) {
+		b.Send(tgbotapi.NewMessage(chat.ID, T(lang, ChatStartMessage)))
+	}
+}
+
+// chatMessageHandler обрабатывает команды настройки рассылки в группах и каналах.
+func (b *TGBot) chatMessageHandler(upd tgbotapi.Update) {
+	msg := upd.Message
+	if msg == nil {
+		msg = upd.ChannelPost
+	}
+	if !msg.IsCommand() {
+		return
+	}
+
+	// в группе с несколькими ботами команда может быть адресована не нам: /start@other_bot
+	command := msg.Command()
+	if mention := msg.CommandWithAt(); strings.Contains(mention, "@") &&
+		!strings.EqualFold(mention, command+"@"+b.Self.UserName) {
+		return
+	}
+	if !slices.Contains([]string{
+		StartCommandText,
+		DigestCommandText,
+		TimezoneCommandText,
+		DigestTimeCommandText,
+	}, command) {
+		return
+	}
+	log.Printf("received command %s in chat %d", command, msg.Chat.ID)
+
+	chat, err := b.getOrAddChat(msg.Chat, updateLang(upd))
+	if err != nil {
+		log.Printf("error while getting chat %d: %s", msg.Chat.ID, err)
+		b.trySend(tgbotapi.NewMessage(msg.Chat.ID, T(updateLang(upd), ErrorUserMessage)))
+		return
+	}
+
+	lang := ChatLang(chat)
+	if !b.isAdminMessage(msg) {
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, NotChatAdminMessage)))
+		return
+	}
+
+	switch command {
+	case StartCommandText, DigestCommandText:
+		b.ChatSubscriptionsHandler(chat)
+	case TimezoneCommandText:
+		b.ChatTimezoneCommandHandler(msg, chat)
+	case DigestTimeCommandText:
+		b.ChatDigestTimeCommandHandler(msg, chat)
+	}
+}
+
+// getOrAddChat достает настройки чата, а если бота добавили до появления
+// рассылок в чатах - сохраняет чат с языком lang.
+func (b *TGBot) getOrAddChat(tgChat *tgbotapi.Chat, lang i18n.Lang) (*models.Chat, error) {
+	chat, err := b.Service.GetChat(tgChat.ID)
+	if err == nil && chat.Title == tgChat.Title {
+		return chat, nil
+	}
+	if err != nil && !errors.Is(err, sqlite.ErrChatNotFound) {
+		return nil, err
+	}
+
+	if chat == nil {
+		chat = &models.Chat{Id: tgChat.ID, Language: string(lang)}
+	}
+	chat.Title = tgChat.Title
+	chat.Type = tgChat.Type
+	if err := b.Service.AddChat(*chat); err != nil {
+		return nil, err
+	}
+
+	return chat, nil
+}
+
+// isAdminMessage проверяет, что сообщение написал администратор чата.
+// В каналах пишут только администраторы, а анонимные администраторы
+// групп пишут от имени самой группы.
+func (b *TGBot) isAdminMessage(msg *tgbotapi.Message) bool {
+	if msg.Chat.IsChannel() {
+		return true
+	}
+	if msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID {
+		return true
+	}
+	if msg.From == nil {
+		return false
+	}
+
+	return b.isChatAdmin(msg.Chat.ID, msg.From.ID)
+}
+
+func (b *TGBot) isChatAdmin(chatId, userId int64) bool {
+	member, err := b.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatId, UserID: userId},
+	})
+	if err != nil {
+		log.Printf("error while getting member %d of chat %d: %s", userId, chatId, err)
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+func (b *TGBot) ChatSubscriptionsHandler(chat *models.Chat) {
+	lang := ChatLang(chat)
+	kinds, err := b.Service.GetChatSubscriptions(chat.Id)
+	if err != nil {
+		log.Printf("error while getting chat subscriptions: %s", err)
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, ErrorUserMessage)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(
+		chat.Id,
+		T(lang, ChatSubscriptionsMessage, ChatDigestTime(chat), ChatLocation(chat)),
+	)
+	msg.ReplyMarkup = GenerateChatSubscriptionsKeyboard(lang, kinds)
+	b.trySend(msg)
+}
+
+func (b *TGBot) ChatTimezoneCommandHandler(msg *tgbotapi.Message, chat *models.Chat) {
+	lang := ChatLang(chat)
+	timezone := strings.TrimSpace(msg.CommandArguments())
+	if timezone == "" {
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, TimezoneUsageMessage, ChatLocation(chat))))
+		return
+	}
+
+	loc, err := ParseTimezone(timezone)
+	if err != nil {
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, InvalidTimezoneMessage, timezone)))
+		return
+	}
+
+	if err = b.Service.SetChatTimezone(chat.Id, loc.String()); err != nil {
+		log.Printf("error while setting timezone for chat %d: %s", chat.Id, err)
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, ErrorUserMessage)))
+		return
+	}
+
+	b.trySend(tgbotapi.NewMessage(
+		chat.Id,
+		T(lang, SuccessTimezoneMessage, loc, ChatDigestTime(chat)),
+	))
+}
+
+func (b *TGBot) ChatDigestTimeCommandHandler(msg *tgbotapi.Message, chat *models.Chat) {
+	lang := ChatLang(chat)
+	digestTime := strings.TrimSpace(msg.CommandArguments())
+	if digestTime == "" {
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, DigestTimeUsageMessage, ChatDigestTime(chat))))
+		return
+	}
+
+	sendAt, err := ParseDigestTime(digestTime)
+	if err != nil {
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, InvalidDigestTimeMessage, digestTime)))
+		return
+	}
+
+	digestTime = time.Time{}.Add(sendAt).Format(DigestTimeLayout)
+	if err = b.Service.SetChatDigestTime(chat.Id, digestTime); err != nil {
+		log.Printf("error while setting digest time for chat %d: %s", chat.Id, err)
+		b.trySend(tgbotapi.NewMessage(chat.Id, T(lang, ErrorUserMessage)))
+		return
+	}
+
+	b.trySend(tgbotapi.NewMessage(
+		chat.Id,
+		T(lang, SuccessDigestTimeMessage, digestTime, ChatLocation(chat)),
+	))
+}
+
+// groupCallbackHandler отвечает на нажатие кнопки в группе, которая работает только в личном чате.
+func (b *TGBot) groupCallbackHandler(upd tgbotapi.Update) {
+	b.Request(tgbotapi.NewCallbackWithAlert(
+		upd.CallbackQuery.ID,
+		T(updateLang(upd), OpenInPrivateMessage, b.Self.UserName),
+	))
+}
+
+// ChatSubscriptionCallbackHandler переключает рассылку чата, если кнопку нажал администратор.
+func (b *TGBot) ChatSubscriptionCallbackHandler(upd tgbotapi.Update, user *models.User) error {
+	msg := upd.CallbackQuery.Message
+	kind, err := ParseChatSubscriptionCallbackData(upd.CallbackData())
+	if err != nil {
+		log.Printf("error while parsing chat subscription callback: %s", err)
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+		return nil
+	}
+
+	if !b.isChatAdmin(msg.Chat.ID, user.Id) {
+		b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, T(UserLang(user), NotChatAdminMessage)))
+		return nil
+	}
+
+	chat, err := b.getOrAddChat(msg.Chat, UserLang(user))
+	if err != nil {
+		return fmt.Errorf("error while getting chat %d: %w", msg.Chat.ID, err)
+	}
+	lang := ChatLang(chat)
+
+	kinds, err := b.Service.GetChatSubscriptions(chat.Id)
+	if err != nil {
+		return fmt.Errorf("error while getting chat subscriptions: %w", err)
+	}
+
+	answer := T(lang, SubscribedMessage, T(lang, SubscriptionKindsText[kind]))
+	if slices.Contains(kinds, kind) {
+		answer = T(lang, UnsubscribedMessage, T(lang, SubscriptionKindsText[kind]))
+		err = b.Service.UnsubscribeChat(chat.Id, kind)
+		kinds = slices.DeleteFunc(kinds, func(k models.SubscriptionKind) bool { return k == kind })
+	} else {
+		err = b.Service.SubscribeChat(chat.Id, kind)
+		kinds = append(kinds, kind)
+	}
+	if err != nil {
+		return fmt.Errorf("error while toggling %s subscription of chat %d: %w", kind, chat.Id, err)
+	}
+	b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, answer))
+
+	keyboard := GenerateChatSubscriptionsKeyboard(lang, kinds)
+	b.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, keyboard))
+
+	return nil
+}
@@ -1,21 +1,205 @@
 package bot
 
 import (
-	"os"
-	"strconv"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"hip-hop-geek/internal/db/sqlite"
 	"hip-hop-geek/internal/models"
 )
 
-func (b *TGBot) StartCommandHandler(upd tgbotapi.Update, user *models.User) {
-	msg := tgbotapi.NewMessage(user.Id, StartCommandMessageText)
-	adminId, _ := strconv.ParseInt(os.Getenv("ADMIN_ID"), 10, 64)
-	keyboard := mainKeyboard
-	if upd.Message.From.ID == adminId {
-		keyboard = adminKeyboard
+func (b *TGBot) StartCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	// вернувшийся после блокировки пользователь снова получает рассылки
+	if err := b.Service.SetUserActive(user.Id, true); err != nil {
+		log.Printf("error while activating user %d: %s", user.Id, err)
+	}
+
+	msg := tgbotapi.NewMessage(user.Id, T(lang, StartCommandMessageText))
+	keyboard := MainKeyboard(lang)
+	if IsAdmin(user.Id) {
+		keyboard = AdminKeyboard(lang)
 	}
 	msg.ReplyMarkup = keyboard
-	b.mustSend(msg)
+	b.trySend(msg)
+
+	return nil
+}
+
+func (b *TGBot) FollowCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	artistName := strings.TrimSpace(upd.Message.CommandArguments())
+	if artistName == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, FollowUsageMessage, FollowCommandText)))
+		return nil
+	}
+
+	artist, err := b.Service.FindArtistByName(artistName)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrArtistNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ArtistNotFoundMessage, artistName)))
+			return nil
+		}
+		return fmt.Errorf("error while finding artist %s: %w", artistName, err)
+	}
+
+	err = b.Service.FollowArtist(user.Id, artist.Id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrAlreadyFollowing) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, AlreadyFollowingMessage, artist.Name)))
+			return nil
+		}
+		return fmt.Errorf("error while following artist %s: %w", artist.Name, err)
+	}
+
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, SuccessFollowMessage, artist.Name)))
+
+	return nil
+}
+
+func (b *TGBot) UnfollowCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	artistName := strings.TrimSpace(upd.Message.CommandArguments())
+	if artistName == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, FollowUsageMessage, UnfollowCommandText)))
+		return nil
+	}
+
+	artist, err := b.Service.FindArtistByName(artistName)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrArtistNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ArtistNotFoundMessage, artistName)))
+			return nil
+		}
+		return fmt.Errorf("error while finding artist %s: %w", artistName, err)
+	}
+
+	if err = b.Service.UnfollowArtist(user.Id, artist.Id); err != nil {
+		return fmt.Errorf("error while unfollowing artist %s: %w", artist.Name, err)
+	}
+
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, SuccessUnfollowMessage, artist.Name)))
+
+	return nil
+}
+
+func (b *TGBot) FollowingCommandHandler(user *models.User) error {
+	lang := UserLang(user)
+	artists, err := b.Service.GetFollowedArtists(user.Id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrArtistNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, NoFollowedArtistsMessage)))
+			return nil
+		}
+		return fmt.Errorf("error while getting followed artists: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(user.Id, T(lang, FollowedArtistsMessage))
+	msg.ReplyMarkup = GenerateFollowedArtistsKeyboard(lang, artists)
+	b.trySend(msg)
+
+	return nil
+}
+
+func (b *TGBot) SearchCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	query := ShortenSearchQuery(strings.TrimSpace(upd.Message.CommandArguments()))
+	if query == "" {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, SearchUsageMessage)))
+		return nil
+	}
+
+	releases, err := b.Service.SearchReleases(query, StandardReleasesLimit, NoOffset)
+	if err != nil {
+		return fmt.Errorf("error while searching releases by %s: %w", query, err)
+	}
+	if len(releases) == 0 {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ReleasesNotFoundMessage)))
+		return nil
+	}
+
+	if err := b.SendListPage(user.Id, lang, NewSearchView(query), releases); err != nil {
+		log.Printf("error while sending search results to %d: %s", user.Id, err)
+	}
+
+	return nil
+}
+
+func (b *TGBot) TimezoneCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	timezone := strings.TrimSpace(upd.Message.CommandArguments())
+	if timezone == "" {
+		b.trySend(tgbotapi.NewMessage(
+			user.Id,
+			T(lang, TimezoneUsageMessage, UserLocation(user)),
+		))
+		return nil
+	}
+
+	loc, err := ParseTimezone(timezone)
+	if err != nil {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, InvalidTimezoneMessage, timezone)))
+		return nil
+	}
+
+	if err = b.Service.SetUserTimezone(user.Id, loc.String()); err != nil {
+		return fmt.Errorf("error while setting timezone for user %d: %w", user.Id, err)
+	}
+
+	b.trySend(tgbotapi.NewMessage(
+		user.Id,
+		T(lang, SuccessTimezoneMessage, loc, UserDigestTime(user)),
+	))
+
+	return nil
+}
+
+func (b *TGBot) DigestTimeCommandHandler(upd tgbotapi.Update, user *models.User) error {
+	lang := UserLang(user)
+	digestTime := strings.TrimSpace(upd.Message.CommandArguments())
+	if digestTime == "" {
+		b.trySend(tgbotapi.NewMessage(
+			user.Id,
+			T(lang, DigestTimeUsageMessage, UserDigestTime(user)),
+		))
+		return nil
+	}
+
+	sendAt, err := ParseDigestTime(digestTime)
+	if err != nil {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(lang, InvalidDigestTimeMessage, digestTime)))
+		return nil
+	}
+
+	// приводим к виду 09:30, даже если пользователь написал 9:30
+	digestTime = time.Time{}.Add(sendAt).Format(DigestTimeLayout)
+	if err = b.Service.SetUserDigestTime(user.Id, digestTime); err != nil {
+		return fmt.Errorf("error while setting digest time for user %d: %w", user.Id, err)
+	}
+
+	b.trySend(tgbotapi.NewMessage(
+		user.Id,
+		T(lang, SuccessDigestTimeMessage, digestTime, UserLocation(user)),
+	))
+
+	return nil
+}
+
+func (b *TGBot) StatsCommandHandler(user *models.User) error {
+	lang := UserLang(user)
+	stats, err := b.Service.GetStats()
+	if err != nil {
+		return fmt.Errorf("error while getting stats: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(user.Id, GenerateStatsText(lang, stats, UserLocation(user)))
+	msg.ParseMode = tgbotapi.ModeHTML
+	b.trySend(msg)
+
+	return nil
 }
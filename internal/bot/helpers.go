@@ -3,28 +3,34 @@ package bot
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/i18n"
 	"hip-hop-geek/internal/models"
 	"hip-hop-geek/internal/utils"
 )
 
-func GenerateCaption(release models.Release) string {
+func GenerateCaption(lang i18n.Lang, release models.Release) string {
 	emoji := SingleEmoji
 	if release.Type == models.Album {
 		emoji = AlbumEmoji
 	}
 	imgCaption := fmt.Sprintf(
-		"%s <b>%s - %s</b> (<i>%d %s %d</i>)",
+		"%s <b>%s - %s</b> (<i>%s</i>)",
 		emoji,
 		release.Artist.Name,
 		release.Title,
-		release.OutDate.Day(),
-		release.OutDate.Month().String(),
-		release.OutDate.Year(),
+		i18n.FormatDate(lang, release.OutDate.Time),
 	)
 
 	return imgCaption
@@ -45,92 +51,104 @@ func GenerateCaptionForTodayRelease(release models.Release) string {
 	return imgCaption
 }
 
-func (b *TGBot) mustSend(msg tgbotapi.Chattable) {
-	if _, err := b.Send(msg); err != nil {
-		log.Fatalf("error while sending message %v: %s", msg, err)
+// GenerateReleasesCaption нумерует релизы страницы, номер совпадает с кнопкой карточки релиза.
+func GenerateReleasesCaption(lang i18n.Lang, releases []models.Release) string {
+	if len(releases) == 0 {
+		return T(lang, ReleasesNotFoundMessage)
 	}
-}
-
-func GenerateReleasesMessage(
-	userId int64,
-	messageType models.MessageIdType,
-	pageCount int,
-	releases []models.Release,
-) tgbotapi.PhotoConfig {
-	inlineKeyboard := GenerateInlineReleasesKeyboard(messageType, pageCount, releases)
 
-	photoUrl := newReleasesPicUrl
-	for _, release := range releases {
-		if release.CoverUrl.IsValid {
-			photoUrl = release.CoverUrl.Value
-			break
-		}
+	caption := make([]string, 0, len(releases))
+	for i, release := range releases {
+		caption = append(caption, fmt.Sprintf(ReleaseNumberText, i+1, GenerateCaption(lang, release)))
 	}
 
-	caption := make([]string, 0, 10)
+	return strings.Join(caption, "\n\n")
+}
 
-	for _, release := range releases {
-		caption = append(caption, GenerateCaption(release))
+// trySend отправляет сообщение, когда результат отправки не нужен вызывающему.
+// Ошибка только логируется: пользователь мог заблокировать бота, это не повод падать.
+func (b *TGBot) trySend(msg tgbotapi.Chattable) {
+	if _, err := b.Send(msg); err != nil {
+		log.Printf("error while sending message %v: %s", msg, err)
 	}
+}
 
-	photoMsg := tgbotapi.NewPhoto(userId, tgbotapi.FileURL(photoUrl))
-	photoMsg.Caption = strings.Join(caption, "\n\n")
+func (b *TGBot) GenerateReleasesEditMessage(
+	lang i18n.Lang,
+	releases []models.Release,
+) tgbotapi.InputMediaPhoto {
+	photoMsg := tgbotapi.NewInputMediaPhoto(b.ReleasesPhoto(releases))
+	photoMsg.Caption = GenerateReleasesCaption(lang, releases)
 	photoMsg.ParseMode = tgbotapi.ModeHTML
-	photoMsg.ReplyMarkup = inlineKeyboard
 
 	return photoMsg
 }
 
-func GenerateReleasesEditMessage(
-	releases []models.Release,
-) tgbotapi.InputMediaPhoto {
+// ReleasesPhoto собирает коллаж из обложек страницы в порядке нумерации релизов.
+// Если коллаж собрать не удалось - берется первая найденная обложка.
+func (b *TGBot) ReleasesPhoto(releases []models.Release) tgbotapi.RequestFileData {
 	photoUrl := newReleasesPicUrl
+	coverUrls := make([]string, 0, len(releases))
 	for _, release := range releases {
 		if release.CoverUrl.IsValid {
-			photoUrl = release.CoverUrl.Value
-			break
+			if photoUrl == newReleasesPicUrl {
+				photoUrl = release.CoverUrl.Value
+			}
+			coverUrls = append(coverUrls, release.CoverUrl.Value)
+		} else {
+			coverUrls = append(coverUrls, "")
 		}
 	}
 
-	caption := make([]string, 0, 10)
+	if photoUrl == newReleasesPicUrl || len(releases) == 1 || b.Collage == nil {
+		return tgbotapi.FileURL(photoUrl)
+	}
 
-	for _, release := range releases {
-		caption = append(caption, GenerateCaption(release))
+	collage, err := b.Collage.MakeCollage(coverUrls)
+	if err != nil {
+		log.Printf("error while making covers collage: %s", err)
+		return tgbotapi.FileURL(photoUrl)
 	}
 
-	photoMsg := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(photoUrl))
-	photoMsg.Caption = strings.Join(caption, "\n\n")
-	photoMsg.ParseMode = tgbotapi.ModeHTML
+	return tgbotapi.FileBytes{Name: CollageFileName, Bytes: collage}
+}
 
-	return photoMsg
+// GenerateReleasesButtonsRows - по пронумерованной кнопке на каждый релиз страницы.
+func GenerateReleasesButtonsRows(
+	releases []models.Release,
+	backData string,
+) [][]tgbotapi.InlineKeyboardButton {
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(releases))
+	for i, release := range releases {
+		buttons = append(
+			buttons,
+			tgbotapi.NewInlineKeyboardButtonData(
+				strconv.Itoa(i+1),
+				GenerateReleaseCallbackData(release.Id, backData),
+			),
+		)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 2)
+	for _, chunk := range utils.ChunkBy(buttons, ReleasesButtonsInRow) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(chunk...))
+	}
+
+	return rows
 }
 
-func GenerateInlineReleasesKeyboard(
-	messageType models.MessageIdType,
+func GeneratePageRow(
 	pageCount int,
-	releases []models.Release,
-) tgbotapi.InlineKeyboardMarkup {
+	hasNext bool,
+	prevData, nextData string,
+) []tgbotapi.InlineKeyboardButton {
 	inlineButtons := make([]tgbotapi.InlineKeyboardButton, 0, 3)
 
 	if pageCount > 1 {
-		switch messageType {
-		case models.ReleasesMessage:
-			inlineButtons = append(
-				inlineButtons,
-				tgbotapi.NewInlineKeyboardButtonData(
-					PrevReleasesButtonText,
-					PreviousReleasesCallbackText,
-				),
-			)
-		case models.TodayReleasesMessage:
-			inlineButtons = append(
-				inlineButtons,
-				tgbotapi.NewInlineKeyboardButtonData(
-					PrevReleasesButtonText,
-					PreviousTodayReleasesCallbackText,
-				),
-			)
-		}
+		inlineButtons = append(
+			inlineButtons,
+			tgbotapi.NewInlineKeyboardButtonData(PrevReleasesButtonText, prevData),
+		)
 	}
 
 	var emojiPage string
@@ -147,46 +165,49 @@ func GenerateInlineReleasesKeyboard(
 		),
 	)
 
-	if len(releases) == StandardReleasesLimit {
-		switch messageType {
-		case models.ReleasesMessage:
-			inlineButtons = append(
-				inlineButtons,
-				tgbotapi.NewInlineKeyboardButtonData(
-					NextReleasesButtonText,
-					NextReleasesCallbackText,
-				),
-			)
-		case models.TodayReleasesMessage:
-			inlineButtons = append(
-				inlineButtons,
-				tgbotapi.NewInlineKeyboardButtonData(
-					NextReleasesButtonText,
-					NextTodayReleasesCallbackText,
-				),
-			)
-		}
+	if hasNext {
+		inlineButtons = append(
+			inlineButtons,
+			tgbotapi.NewInlineKeyboardButtonData(NextReleasesButtonText, nextData),
+		)
 	}
 
-	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(inlineButtons...),
-	)
-	return inlineKeyboard
+	return inlineButtons
+}
+
+func GenerateYearsKeyboard(years []int) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(years))
+	for _, year := range years {
+		buttons = append(
+			buttons,
+			tgbotapi.NewInlineKeyboardButtonData(
+				strconv.Itoa(year),
+				GenerateCalendarCallbackData(year),
+			),
+		)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(buttons)/3+1)
+	for _, chunk := range utils.ChunkBy(buttons, 3) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(chunk...))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 func GenerateYearByMonthKeyboard(
+	lang i18n.Lang,
 	year int,
+	filter models.ReleaseType,
 ) tgbotapi.InlineKeyboardMarkup {
 	buttons := make([]tgbotapi.InlineKeyboardButton, 0, 12)
 
-	for monthStr, monthInt := range utils.AllMonthsMapping {
-		log.Println(monthStr)
-		log.Println(monthInt)
+	for _, month := range utils.AllMonthsInt {
 		buttons = append(
 			buttons,
 			tgbotapi.NewInlineKeyboardButtonData(
-				monthStr,
-				fmt.Sprintf("%d.%d", year, monthInt),
+				i18n.ShortMonthName(lang, month),
+				NewMonthView(year, month, filter).Encode(),
 			),
 		)
 	}
@@ -204,5 +225,648 @@ func GenerateYearByMonthKeyboard(
 		tgbotapi.NewInlineKeyboardRow(
 			buttons[9:12]...,
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				T(lang, BackToYearsButtonText),
+				CalendarCallbackPrefix,
+			),
+		),
+	)
+}
+
+// GenerateCalendarCallbackData кодирует шаг выбора в календаре:
+// "cal:" - выбор года, "cal:2024" - выбор месяца. Страница месяца - это уже список релизов.
+func GenerateCalendarCallbackData(year int) string {
+	if year == 0 {
+		return CalendarCallbackPrefix
+	}
+
+	return fmt.Sprintf("%s%d", CalendarCallbackPrefix, year)
+}
+
+func ParseCalendarCallbackData(data string) (int, error) {
+	data = strings.TrimPrefix(data, CalendarCallbackPrefix)
+	if data == "" {
+		return 0, nil
+	}
+
+	year, err := strconv.Atoi(data)
+	if err != nil {
+		return 0, fmt.Errorf("invalid calendar callback data %s: %w", data, err)
+	}
+
+	return year, nil
+}
+
+// GenerateReleaseCallbackData открывает карточку релиза, после id идет callback страницы списка,
+// на которую ведет кнопка возврата. Слишком длинный запрос поиска обрезается.
+func GenerateReleaseCallbackData(releaseId int, backData string) string {
+	return TruncateCallbackData(fmt.Sprintf("%s%d:%s", ReleaseCallbackPrefix, releaseId, backData))
+}
+
+func ParseReleaseCallbackData(data string) (int, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(data, ReleaseCallbackPrefix), ":", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("invalid release callback data: %s", data)
+	}
+
+	releaseId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid release id in callback data %s: %w", data, err)
+	}
+
+	return releaseId, parts[1], nil
+}
+
+func GenerateReleaseCardCaption(lang i18n.Lang, release models.Release) string {
+	emoji, releaseType := SingleEmoji, SingleTypeText
+	if release.Type == models.Album {
+		emoji, releaseType = AlbumEmoji, AlbumTypeText
+	}
+
+	return fmt.Sprintf(
+		ReleaseCardText,
+		emoji,
+		T(lang, releaseType),
+		release.Artist.Name,
+		release.Title,
+		i18n.WeekdayName(lang, release.OutDate.Weekday()),
+		i18n.FormatDate(lang, release.OutDate.Time),
+	)
+}
+
+func GenerateReleaseCardMessage(lang i18n.Lang, release models.Release) tgbotapi.InputMediaPhoto {
+	photoUrl := newReleasesPicUrl
+	if release.CoverUrl.IsValid {
+		photoUrl = release.CoverUrl.Value
+	}
+
+	media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(photoUrl))
+	media.Caption = GenerateReleaseCardCaption(lang, release)
+	media.ParseMode = tgbotapi.ModeHTML
+
+	return media
+}
+
+// ReleaseCardState - то, что кнопки карточки релиза показывают конкретному пользователю.
+type ReleaseCardState struct {
+	IsFollowing bool
+	// IsSaved - релиз в личном списке пользователя /mylist
+	IsSaved bool
+	// CanRemind - релиз еще не вышел, и о нем можно напомнить
+	CanRemind   bool
+	HasReminder bool
+	// BackData - callback возврата к списку, пустой, если карточка открыта не из списка
+	BackData string
+}
+
+// GenerateReleaseCardKeyboard - подписка на исполнителя, сохранение в личный список,
+// напоминание о будущем релизе, ссылки на поиск релиза в стримингах и, если карточка
+// открыта из списка, возврат к списку.
+func GenerateReleaseCardKeyboard(
+	lang i18n.Lang,
+	release models.Release,
+	artist *db.ArtistDB,
+	state ReleaseCardState,
+) tgbotapi.InlineKeyboardMarkup {
+	rows := GenerateFollowArtistKeyboard(lang, artist, state.IsFollowing).InlineKeyboard
+	actions := tgbotapi.NewInlineKeyboardRow(GenerateSaveButton(lang, release.Id, state.IsSaved))
+	if state.CanRemind {
+		actions = append(actions, GenerateReminderButton(lang, release.Id, state.HasReminder))
+	}
+	rows = append(rows, actions)
+
+	searchQuery := fmt.Sprintf("%s %s", release.Artist.Name, release.Title)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL(
+			YoutubeButtonText,
+			fmt.Sprintf(YoutubeSearchUrl, url.QueryEscape(searchQuery)),
+		),
+		tgbotapi.NewInlineKeyboardButtonURL(
+			SpotifyButtonText,
+			fmt.Sprintf(SpotifySearchUrl, url.PathEscape(searchQuery)),
+		),
+		tgbotapi.NewInlineKeyboardButtonURL(
+			AppleMusicButtonText,
+			fmt.Sprintf(AppleMusicSearchUrl, url.QueryEscape(searchQuery)),
+		),
+	))
+
+	if state.BackData != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(T(lang, BackToListButtonText), state.BackData),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// ReplaceFollowButton меняет в клавиатуре кнопку подписки на исполнителя,
+// остальные кнопки сообщения остаются на месте.
+func ReplaceFollowButton(
+	lang i18n.Lang,
+	keyboard *tgbotapi.InlineKeyboardMarkup,
+	artist *db.ArtistDB,
+	isFollowing bool,
+) tgbotapi.InlineKeyboardMarkup {
+	followButton := GenerateFollowArtistKeyboard(lang, artist, isFollowing).InlineKeyboard[0][0]
+	return replaceButton(
+		keyboard,
+		followButton,
+		fmt.Sprintf("%s%d", FollowArtistCallbackPrefix, artist.Id),
+		fmt.Sprintf("%s%d", UnfollowArtistCallbackPrefix, artist.Id),
+	)
+}
+
+// ReplaceReminderButton меняет в клавиатуре карточки кнопку напоминания о релизе.
+func ReplaceReminderButton(
+	lang i18n.Lang,
+	keyboard *tgbotapi.InlineKeyboardMarkup,
+	releaseId int,
+	hasReminder bool,
+) tgbotapi.InlineKeyboardMarkup {
+	return replaceButton(
+		keyboard,
+		GenerateReminderButton(lang, releaseId, hasReminder),
+		fmt.Sprintf("%s%d", RemindCallbackPrefix, releaseId),
+		fmt.Sprintf("%s%d", UnremindCallbackPrefix, releaseId),
+	)
+}
+
+// ReplaceSaveButton меняет в клавиатуре карточки кнопку сохранения релиза в личный список.
+func ReplaceSaveButton(
+	lang i18n.Lang,
+	keyboard *tgbotapi.InlineKeyboardMarkup,
+	releaseId int,
+	isSaved bool,
+) tgbotapi.InlineKeyboardMarkup {
+	return replaceButton(
+		keyboard,
+		GenerateSaveButton(lang, releaseId, isSaved),
+		fmt.Sprintf("%s%d", SaveCallbackPrefix, releaseId),
+		fmt.Sprintf("%s%d", UnsaveCallbackPrefix, releaseId),
 	)
 }
+
+// replaceButton ставит newButton на место кнопок с callback data из datas.
+func replaceButton(
+	keyboard *tgbotapi.InlineKeyboardMarkup,
+	newButton tgbotapi.InlineKeyboardButton,
+	datas ...string,
+) tgbotapi.InlineKeyboardMarkup {
+	if keyboard == nil {
+		return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(newButton))
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(keyboard.InlineKeyboard))
+	for _, row := range keyboard.InlineKeyboard {
+		newRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, button := range row {
+			if button.CallbackData != nil && slices.Contains(datas, *button.CallbackData) {
+				button = newButton
+			}
+			newRow = append(newRow, button)
+		}
+		rows = append(rows, newRow)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func GenerateSaveButton(
+	lang i18n.Lang,
+	releaseId int,
+	isSaved bool,
+) tgbotapi.InlineKeyboardButton {
+	if isSaved {
+		return tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, UnsaveButtonText),
+			fmt.Sprintf("%s%d", UnsaveCallbackPrefix, releaseId),
+		)
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(
+		T(lang, SaveButtonText),
+		fmt.Sprintf("%s%d", SaveCallbackPrefix, releaseId),
+	)
+}
+
+func GenerateReminderButton(
+	lang i18n.Lang,
+	releaseId int,
+	hasReminder bool,
+) tgbotapi.InlineKeyboardButton {
+	if hasReminder {
+		return tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, UnremindButtonText),
+			fmt.Sprintf("%s%d", UnremindCallbackPrefix, releaseId),
+		)
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(
+		T(lang, RemindButtonText),
+		fmt.Sprintf("%s%d", RemindCallbackPrefix, releaseId),
+	)
+}
+
+func GenerateFollowArtistKeyboard(
+	lang i18n.Lang,
+	artist *db.ArtistDB,
+	isFollowing bool,
+) tgbotapi.InlineKeyboardMarkup {
+	button := tgbotapi.NewInlineKeyboardButtonData(
+		T(lang, FollowButtonText, artist.Name),
+		fmt.Sprintf("%s%d", FollowArtistCallbackPrefix, artist.Id),
+	)
+	if isFollowing {
+		button = tgbotapi.NewInlineKeyboardButtonData(
+			T(lang, UnfollowButtonText, artist.Name),
+			fmt.Sprintf("%s%d", UnfollowArtistCallbackPrefix, artist.Id),
+		)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button))
+}
+
+func GenerateFollowedArtistsKeyboard(
+	lang i18n.Lang,
+	artists []*db.ArtistDB,
+) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(artists))
+	for _, artist := range artists {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				T(lang, UnfollowButtonText, artist.Name),
+				fmt.Sprintf("%s%d", UnfollowArtistCallbackPrefix, artist.Id),
+			),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// TruncateCallbackData обрезает данные до лимита Telegram, не разрывая символы.
+func TruncateCallbackData(data string) string {
+	return truncateBytes(data, CallbackDataMaxLen)
+}
+
+func truncateBytes(data string, maxLen int) string {
+	if len(data) <= maxLen {
+		return data
+	}
+
+	end := 0
+	for i, r := range data {
+		if i+utf8.RuneLen(r) > maxLen {
+			break
+		}
+		end = i + utf8.RuneLen(r)
+	}
+
+	return data[:end]
+}
+
+// GenerateUpcomingCaption группирует релизы по дням, над каждым днем пишется дата.
+// Релизы должны быть отсортированы по дате.
+func GenerateUpcomingCaption(lang i18n.Lang, days int, releases []models.Release) string {
+	caption := make([]string, 0, len(releases)+1)
+	caption = append(caption, "<b>"+T(lang, UpcomingReleasesMessage, days)+"</b>")
+
+	var currentDate time.Time
+	for i, release := range releases {
+		if !release.OutDate.Equal(currentDate) {
+			currentDate = release.OutDate.Time
+			caption = append(caption, fmt.Sprintf(
+				"\n<u>%s, %s</u>",
+				i18n.WeekdayName(lang, currentDate.Weekday()),
+				i18n.FormatDayMonth(lang, currentDate),
+			))
+		}
+		caption = append(
+			caption,
+			fmt.Sprintf(ReleaseNumberText, i+1, GenerateCaptionForTodayRelease(release)),
+		)
+	}
+
+	return strings.Join(caption, "\n")
+}
+
+func GenerateSubscriptionsKeyboard(
+	lang i18n.Lang,
+	kinds []models.SubscriptionKind,
+) tgbotapi.InlineKeyboardMarkup {
+	return generateSubscriptionsKeyboard(lang, models.AllSubscriptionKinds, kinds, SubscriptionCallbackPrefix)
+}
+
+// GenerateChatSubscriptionsKeyboard - рассылки группы или канала, без личных уведомлений.
+func GenerateChatSubscriptionsKeyboard(
+	lang i18n.Lang,
+	kinds []models.SubscriptionKind,
+) tgbotapi.InlineKeyboardMarkup {
+	return generateSubscriptionsKeyboard(lang, models.ChatSubscriptionKinds, kinds, ChatSubscriptionCallbackPrefix)
+}
+
+func generateSubscriptionsKeyboard(
+	lang i18n.Lang,
+	available, enabled []models.SubscriptionKind,
+	prefix string,
+) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(available))
+	for _, kind := range available {
+		text := fmt.Sprintf(SubscriptionOffButtonText, T(lang, SubscriptionKindsText[kind]))
+		if slices.Contains(enabled, kind) {
+			text = fmt.Sprintf(SubscriptionOnButtonText, T(lang, SubscriptionKindsText[kind]))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, prefix+string(kind)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func ParseSubscriptionCallbackData(data string) (models.SubscriptionKind, error) {
+	return parseSubscriptionCallbackData(data, SubscriptionCallbackPrefix, models.AllSubscriptionKinds)
+}
+
+func ParseChatSubscriptionCallbackData(data string) (models.SubscriptionKind, error) {
+	return parseSubscriptionCallbackData(data, ChatSubscriptionCallbackPrefix, models.ChatSubscriptionKinds)
+}
+
+func parseSubscriptionCallbackData(
+	data, prefix string,
+	available []models.SubscriptionKind,
+) (models.SubscriptionKind, error) {
+	kind := models.SubscriptionKind(strings.TrimPrefix(data, prefix))
+	if !strings.HasPrefix(data, prefix) || !slices.Contains(available, kind) {
+		return "", fmt.Errorf("invalid subscription callback data %s", data)
+	}
+
+	return kind, nil
+}
+
+// AdminId - администратор бота из ADMIN_ID, 0 - если не задан.
+func AdminId() int64 {
+	adminId, _ := strconv.ParseInt(os.Getenv("ADMIN_ID"), 10, 64)
+	return adminId
+}
+
+func IsAdmin(userId int64) bool {
+	adminId := AdminId()
+	return adminId != 0 && userId == adminId
+}
+
+// notifyAdmin сообщает администратору об ошибке, которую пользователь не видит целиком.
+func (b *TGBot) notifyAdmin(text string) {
+	if adminId := AdminId(); adminId != 0 {
+		b.trySend(tgbotapi.NewMessage(adminId, text))
+	}
+}
+
+// AdminAlertInterval - как часто администратор получает одну и ту же ошибку фоновой задачи.
+const AdminAlertInterval = time.Hour
+
+// adminAlerts помнит, когда администратору последний раз отправлялась каждая ошибка.
+type adminAlerts struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// allow возвращает true, если text не отправлялся последние AdminAlertInterval.
+func (a *adminAlerts) allow(text string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sent == nil {
+		a.sent = make(map[string]time.Time)
+	}
+	for sentText, sentAt := range a.sent {
+		if now.Sub(sentAt) >= AdminAlertInterval {
+			delete(a.sent, sentText)
+		}
+	}
+
+	if _, ok := a.sent[text]; ok {
+		return false
+	}
+	a.sent[text] = now
+	return true
+}
+
+// UserLang возвращает язык пользователя, пока он не выбран - язык по умолчанию.
+func UserLang(user *models.User) i18n.Lang {
+	lang, ok := i18n.Parse(user.Language)
+	if !ok {
+		return i18n.DefaultLang
+	}
+
+	return lang
+}
+
+func GenerateLanguageKeyboard(current i18n.Lang) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(i18n.Supported))
+	for _, lang := range i18n.Supported {
+		text := LanguageNames[lang]
+		if lang == current {
+			text = fmt.Sprintf(SelectedButtonText, text)
+		}
+		buttons = append(
+			buttons,
+			tgbotapi.NewInlineKeyboardButtonData(text, LanguageCallbackPrefix+string(lang)),
+		)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(buttons)
+}
+
+func ParseLanguageCallbackData(data string) (i18n.Lang, error) {
+	lang, ok := i18n.Parse(strings.TrimPrefix(data, LanguageCallbackPrefix))
+	if !ok {
+		return "", fmt.Errorf("invalid language callback data %s", data)
+	}
+
+	return lang, nil
+}
+
+// ChatLang возвращает язык группы или канала, выбранный при добавлении бота.
+func ChatLang(chat *models.Chat) i18n.Lang {
+	lang, ok := i18n.Parse(chat.Language)
+	if !ok {
+		return i18n.DefaultLang
+	}
+
+	return lang
+}
+
+// UserLocation возвращает часовой пояс пользователя, если он не задан
+// или не загружается - часовой пояс сервера.
+func UserLocation(user *models.User) *time.Location {
+	return loadLocation(user.Timezone)
+}
+
+func ChatLocation(chat *models.Chat) *time.Location {
+	return loadLocation(chat.Timezone)
+}
+
+// locations - загруженные часовые пояса, рассылка проверяет их у всех подписчиков каждую минуту.
+var locations sync.Map
+
+func loadLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(timezone); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("error while loading timezone %s: %s", timezone, err)
+		loc = time.Local
+	}
+	locations.Store(timezone, loc)
+
+	return loc
+}
+
+// ParseTimezone проверяет часовой пояс, который прислали в /timezone.
+func ParseTimezone(timezone string) (*time.Location, error) {
+	// "Local" и "UTC" LoadLocation тоже принимает, но "Local" - это пояс сервера
+	if timezone == "Local" {
+		return nil, fmt.Errorf("server timezone %s is not allowed", timezone)
+	}
+
+	return time.LoadLocation(timezone)
+}
+
+// UserNow возвращает текущее время в часовом поясе пользователя,
+// по нему определяется, какой день считать "сегодня".
+func UserNow(user *models.User) time.Time {
+	return time.Now().In(UserLocation(user))
+}
+
+// DefaultDigestTime собирает время рассылки по умолчанию из SEND_SUBS_HOUR и SEND_SUBS_MINUTE.
+func DefaultDigestTime() string {
+	sendHour, _ := strconv.Atoi(os.Getenv("SEND_SUBS_HOUR"))
+	sendMinute, _ := strconv.Atoi(os.Getenv("SEND_SUBS_MINUTE"))
+	return fmt.Sprintf("%02d:%02d", sendHour, sendMinute)
+}
+
+func UserDigestTime(user *models.User) string {
+	if user.DigestTime == "" {
+		return DefaultDigestTime()
+	}
+	return user.DigestTime
+}
+
+func ChatDigestTime(chat *models.Chat) string {
+	if chat.DigestTime == "" {
+		return DefaultDigestTime()
+	}
+	return chat.DigestTime
+}
+
+// JoinYears - года через запятую для сообщений администратору.
+func JoinYears(years []int) string {
+	parts := make([]string, 0, len(years))
+	for _, year := range years {
+		parts = append(parts, strconv.Itoa(year))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseDigestTime разбирает время в формате 15:04 и возвращает смещение от начала дня.
+func ParseDigestTime(digestTime string) (time.Duration, error) {
+	t, err := time.Parse(DigestTimeLayout, digestTime)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsUpcomingRelease - релиз выходит позже сегодняшнего дня now.
+func IsUpcomingRelease(release models.Release, now time.Time) bool {
+	year, month, day := now.Date()
+	return release.OutDate.After(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// IsReminderDue - релиз уже вышел по часовому поясу пользователя и наступило время его рассылки.
+// Напоминание отправляется независимо от того, пришла ли сегодня сама рассылка,
+// а после неудачной отправки повторяется только на следующий день.
+func IsReminderDue(reminder models.Reminder, release models.Release, now time.Time) bool {
+	user := reminder.User
+	return !IsUpcomingRelease(release, now.In(UserLocation(user))) &&
+		isDigestDue(UserLocation(user), UserDigestTime(user), reminder.LastAttemptDate, now)
+}
+
+// IsDigestDue проверяет, пора ли отправить пользователю ежедневную рассылку:
+// наступило время рассылки по его часовому поясу, прошло не больше DigestGracePeriod
+// и сегодня рассылка еще не отправлялась.
+func IsDigestDue(user *models.User, now time.Time) bool {
+	return isDigestDue(UserLocation(user), UserDigestTime(user), user.LastDigestDate, now)
+}
+
+// IsChatDigestDue - то же, что IsDigestDue, для группы или канала.
+func IsChatDigestDue(chat *models.Chat, now time.Time) bool {
+	return isDigestDue(ChatLocation(chat), ChatDigestTime(chat), chat.LastDigestDate, now)
+}
+
+func isDigestDue(loc *time.Location, digestTime, lastDigestDate string, now time.Time) bool {
+	localNow := now.In(loc)
+	if lastDigestDate == localNow.Format(DigestDateLayout) {
+		return false
+	}
+
+	sendAt, err := ParseDigestTime(digestTime)
+	if err != nil {
+		log.Printf("error while parsing digest time %s: %s", digestTime, err)
+		return false
+	}
+
+	year, month, day := localNow.Date()
+	sinceMidnight := localNow.Sub(time.Date(year, month, day, 0, 0, 0, 0, localNow.Location()))
+
+	return sinceMidnight >= sendAt && sinceMidnight < sendAt+DigestGracePeriod
+}
+
+// GenerateStatsText собирает сводку для администратора, время показывается в часовом поясе loc.
+func GenerateStatsText(lang i18n.Lang, stats *models.Stats, loc *time.Location) string {
+	text := []string{
+		T(lang, StatsUsersMessage, stats.Users, stats.InactiveUsers, stats.Chats),
+		"",
+		T(lang, StatsSubscribersMessage),
+	}
+	for _, kind := range models.AllSubscriptionKinds {
+		text = append(text, fmt.Sprintf("%s: %d", T(lang, SubscriptionKindsText[kind]), stats.Subscribers[kind]))
+	}
+
+	text = append(text, "", T(lang, StatsReleasesMessage))
+	for _, year := range stats.ReleasesByYear {
+		text = append(text, T(lang, StatsYearReleasesMessage, year.Year, year.Albums, year.Singles))
+	}
+	text = append(text, T(lang, StatsLibraryMessage, stats.ReleasesWithoutCover, stats.Artists), "")
+
+	if run := stats.LastUpdaterRun; run != nil {
+		text = append(text, T(
+			lang,
+			StatsUpdaterRunMessage,
+			run.FinishedAt.In(loc).Format(StatsTimeLayout),
+			run.FinishedAt.Sub(run.StartedAt).Round(time.Second),
+			run.NewReleases,
+		))
+	} else {
+		text = append(text, T(lang, StatsNoUpdaterRunMessage))
+	}
+
+	if run := stats.LastDigestRun; run != nil {
+		text = append(text, T(
+			lang,
+			StatsDigestRunMessage,
+			run.FinishedAt.In(loc).Format(StatsTimeLayout),
+			run.Recipients,
+			run.Failed,
+		))
+	} else {
+		text = append(text, T(lang, StatsNoDigestRunMessage))
+	}
+
+	return strings.Join(text, "\n")
+}
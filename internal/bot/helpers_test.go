@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestParseDigestTime(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		isError  bool
+	}{
+		{"two digits hour", "09:30", 9*time.Hour + 30*time.Minute, false},
+		{"one digit hour", "9:05", 9*time.Hour + 5*time.Minute, false},
+		{"midnight", "00:00", 0, false},
+		{"wrong hour", "25:00", 0, true},
+		{"not a time", "morning", 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseDigestTime(tc.value)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestIsDigestDue(t *testing.T) {
+	// 10:15 UTC = 17:15 Asia/Tomsk = 12:15 Europe/Berlin
+	now := time.Date(2024, time.August, 11, 10, 15, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		user     models.User
+		expected bool
+	}{
+		{
+			"digest time just passed in user timezone",
+			models.User{Timezone: "Europe/Berlin", DigestTime: "12:00"},
+			true,
+		},
+		{
+			"digest time not reached in user timezone",
+			models.User{Timezone: "Europe/Berlin", DigestTime: "17:00"},
+			false,
+		},
+		{
+			"same digest time in another timezone",
+			models.User{Timezone: "Asia/Tomsk", DigestTime: "17:00"},
+			true,
+		},
+		{
+			"already sent today",
+			models.User{Timezone: "Asia/Tomsk", DigestTime: "17:00", LastDigestDate: "2024-08-11"},
+			false,
+		},
+		{
+			"sent yesterday",
+			models.User{Timezone: "Asia/Tomsk", DigestTime: "17:00", LastDigestDate: "2024-08-10"},
+			true,
+		},
+		{
+			"grace period is over",
+			models.User{Timezone: "Europe/Berlin", DigestTime: "09:00"},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsDigestDue(&tc.user, now))
+		})
+	}
+}
+
+func TestGenerateStatsText(t *testing.T) {
+	finished := time.Date(2024, time.August, 12, 8, 1, 30, 0, time.UTC)
+	stats := &models.Stats{
+		Users:         12,
+		InactiveUsers: 3,
+		Chats:         2,
+		Artists:       40,
+		Subscribers:   map[models.SubscriptionKind]int{models.HistorySubscription: 5},
+		ReleasesByYear: []models.YearReleasesStats{
+			{Year: 2024, Albums: 3, Singles: 7},
+		},
+		ReleasesWithoutCover: 4,
+		LastUpdaterRun: &models.UpdaterRun{
+			StartedAt:   finished.Add(-90 * time.Second),
+			FinishedAt:  finished,
+			NewReleases: 2,
+		},
+	}
+
+	loc, _ := time.LoadLocation("Asia/Tomsk")
+	text := GenerateStatsText(i18n.Ru, stats, loc)
+	assert.Contains(t, text, "Пользователей: 12 (заблокировали бота: 3), групп и каналов: 2")
+	assert.Contains(t, text, "Сегодня в истории хип хопа: 5")
+	assert.Contains(t, text, "Релизы дня: 0")
+	assert.Contains(t, text, "2024: 3 альбомов, 7 синглов")
+	assert.Contains(t, text, "Без обложки: 4, исполнителей: 40")
+	assert.Contains(t, text, "Последнее обновление: 12.08.2024 15:01, заняло 1m30s, новых релизов: 2")
+	assert.Contains(t, text, "Рассылка еще не отправлялась")
+}
+
+func TestReleaseCallbackData(t *testing.T) {
+	t.Run("back to month page", func(t *testing.T) {
+		backData := NewMonthView(2024, time.March, models.AnyType).WithPage(2).Encode()
+		data := GenerateReleaseCallbackData(123456, backData)
+		assert.Equal(t, "rel:123456:ls1:m:2024.3.0.2", data)
+
+		releaseId, gotBackData, err := ParseReleaseCallbackData(data)
+		assert.NoError(t, err)
+		assert.Equal(t, 123456, releaseId)
+		assert.Equal(t, backData, gotBackData)
+	})
+
+	t.Run("long search query is truncated", func(t *testing.T) {
+		query := "кендрик ламар to pimp a butterfly deluxe edition"
+		data := GenerateReleaseCallbackData(123456, NewSearchView(query).Encode())
+		assert.LessOrEqual(t, len(data), CallbackDataMaxLen)
+		assert.True(t, utf8.ValidString(data))
+
+		_, backData, err := ParseReleaseCallbackData(data)
+		assert.NoError(t, err)
+		view, err := DecodeListView(backData)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, view.Page)
+		assert.True(t, strings.HasPrefix(query, view.Query))
+	})
+
+	t.Run("invalid release id", func(t *testing.T) {
+		_, _, err := ParseReleaseCallbackData("rel:abc:ls1:u:7.0.1")
+		assert.Error(t, err)
+	})
+}
+
+func TestReplaceFollowButton(t *testing.T) {
+	artist := &db.ArtistDB{Id: 7, Name: "Drake"}
+	release := models.Release{Id: 1, Artist: models.Artist{Name: "Drake"}, Title: "Scorpion"}
+	keyboard := GenerateReleaseCardKeyboard(i18n.En, release, artist, ReleaseCardState{
+		BackData: "ls1:u:7.0.1",
+	})
+
+	got := ReplaceFollowButton(i18n.En, &keyboard, artist, true)
+	assert.Equal(t, "unfollow:7", *got.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, keyboard.InlineKeyboard[1:], got.InlineKeyboard[1:])
+}
+
+func TestReleaseReminder(t *testing.T) {
+	artist := &db.ArtistDB{Id: 7, Name: "Kendrick Lamar"}
+	release := models.Release{
+		Id:      1,
+		Artist:  models.Artist{Name: "Kendrick Lamar"},
+		Title:   "GNX",
+		OutDate: types.NewCustomDate(2024, time.November, 22),
+	}
+
+	t.Run("reminder button only on upcoming releases", func(t *testing.T) {
+		assert.True(t, IsUpcomingRelease(release, time.Date(2024, time.November, 21, 23, 0, 0, 0, time.UTC)))
+		assert.False(t, IsUpcomingRelease(release, time.Date(2024, time.November, 22, 1, 0, 0, 0, time.UTC)))
+
+		keyboard := GenerateReleaseCardKeyboard(i18n.En, release, artist, ReleaseCardState{})
+		assert.Len(t, keyboard.InlineKeyboard[1], 1)
+
+		keyboard = GenerateReleaseCardKeyboard(i18n.En, release, artist, ReleaseCardState{CanRemind: true})
+		assert.Len(t, keyboard.InlineKeyboard[1], 2)
+		assert.Equal(t, "remind:1", *keyboard.InlineKeyboard[1][1].CallbackData)
+
+		got := ReplaceReminderButton(i18n.En, &keyboard, release.Id, true)
+		assert.Equal(t, "unremind:1", *got.InlineKeyboard[1][1].CallbackData)
+		assert.Equal(t, keyboard.InlineKeyboard[1][0], got.InlineKeyboard[1][0])
+	})
+
+	t.Run("reminder is due on release day at digest time", func(t *testing.T) {
+		// 22 ноября 09:15 UTC = 18:15 Asia/Tokyo, в Гонолулу еще 21 ноября 23:15
+		now := time.Date(2024, time.November, 22, 9, 15, 0, 0, time.UTC)
+		tokyo := &models.User{Timezone: "Asia/Tokyo", DigestTime: "18:00", LastDigestDate: "2024-11-22"}
+		honolulu := &models.User{Timezone: "Pacific/Honolulu", DigestTime: "23:00"}
+		late := &models.User{Timezone: "Asia/Tokyo", DigestTime: "10:00"}
+
+		assert.True(t, IsReminderDue(models.Reminder{User: tokyo}, release, now))
+		assert.False(t, IsReminderDue(models.Reminder{User: honolulu}, release, now))
+		assert.False(t, IsReminderDue(models.Reminder{User: late}, release, now))
+
+		// сегодня отправить уже не удалось, следующая попытка - завтра
+		failed := models.Reminder{User: tokyo, LastAttemptDate: "2024-11-22"}
+		assert.False(t, IsReminderDue(failed, release, now))
+		assert.True(t, IsReminderDue(failed, release, now.AddDate(0, 0, 1)))
+	})
+}
+
+func TestReplaceSaveButton(t *testing.T) {
+	artist := &db.ArtistDB{Id: 7, Name: "Drake"}
+	release := models.Release{Id: 1, Artist: models.Artist{Name: "Drake"}, Title: "Scorpion"}
+	keyboard := GenerateReleaseCardKeyboard(i18n.En, release, artist, ReleaseCardState{IsSaved: true})
+	assert.Equal(t, "unsave:1", *keyboard.InlineKeyboard[1][0].CallbackData)
+
+	got := ReplaceSaveButton(i18n.En, &keyboard, release.Id, false)
+	assert.Equal(t, "save:1", *got.InlineKeyboard[1][0].CallbackData)
+	assert.Equal(t, keyboard.InlineKeyboard[0], got.InlineKeyboard[0])
+}
+
+func TestGenerateReleasesButtonsRows(t *testing.T) {
+	releases := make([]models.Release, 7)
+	for i := range releases {
+		releases[i].Id = i + 1
+	}
+
+	rows := GenerateReleasesButtonsRows(releases, "ls1:u:7.0.1")
+	assert.Len(t, rows, 2)
+	assert.Len(t, rows[0], ReleasesButtonsInRow)
+	assert.Equal(t, tgbotapi.NewInlineKeyboardButtonData("6", "rel:6:ls1:u:7.0.1"), rows[1][0])
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+const (
+	InlineResultsLimit = 20
+	InlineCacheSeconds = 300
+	// после ошибки Telegram быстро повторит запрос, а не покажет пустой ответ 5 минут
+	InlineErrorCacheSeconds = 5
+)
+
+// inlineQueryHandler отвечает на запросы вида "@bot kendrick" из любого чата.
+// Пустой запрос показывает сегодняшние релизы.
+func (b *TGBot) inlineQueryHandler(upd tgbotapi.Update) {
+	query := strings.TrimSpace(upd.InlineQuery.Query)
+	offset, _ := strconv.Atoi(upd.InlineQuery.Offset)
+	lang := i18n.FromCode(upd.InlineQuery.From.LanguageCode)
+
+	var releases []models.Release
+	var err error
+	if query == "" {
+		now := time.Now().UTC()
+		releases, err = b.Service.GetReleasesByDay(
+			now.Year(), now.Month(), now.Day(),
+			models.AnyType,
+			InlineResultsLimit,
+			offset,
+		)
+	} else {
+		releases, err = b.Service.SearchReleases(query, InlineResultsLimit, offset)
+	}
+	if err != nil {
+		log.Printf("error while getting releases for inline query %s: %s", query, err)
+	}
+
+	answer := GenerateInlineAnswer(upd.InlineQuery.ID, lang, releases, offset, err)
+	if _, err := b.Request(answer); err != nil {
+		log.Printf("error while answering inline query %s: %s", query, err)
+	}
+}
+
+// GenerateInlineAnswer отвечает на inline-запрос даже при ошибке: без ответа Telegram
+// долго показывает загрузку. Ошибка дает пустой список с коротким кешем.
+func GenerateInlineAnswer(
+	queryId string,
+	lang i18n.Lang,
+	releases []models.Release,
+	offset int,
+	err error,
+) tgbotapi.InlineConfig {
+	if err != nil {
+		return tgbotapi.InlineConfig{
+			InlineQueryID: queryId,
+			Results:       []interface{}{},
+			CacheTime:     InlineErrorCacheSeconds,
+		}
+	}
+
+	results := make([]interface{}, 0, len(releases))
+	for _, release := range releases {
+		results = append(results, GenerateInlineReleaseResult(lang, release))
+	}
+
+	nextOffset := ""
+	if len(releases) == InlineResultsLimit {
+		nextOffset = strconv.Itoa(offset + InlineResultsLimit)
+	}
+
+	return tgbotapi.InlineConfig{
+		InlineQueryID: queryId,
+		Results:       results,
+		CacheTime:     InlineCacheSeconds,
+		NextOffset:    nextOffset,
+	}
+}
+
+func GenerateInlineReleaseResult(lang i18n.Lang, release models.Release) interface{} {
+	id := strconv.Itoa(release.Id)
+	title := fmt.Sprintf("%s - %s", release.Artist.Name, release.Title)
+	description := i18n.FormatDate(lang, release.OutDate.Time)
+
+	if release.CoverUrl.IsValid {
+		result := tgbotapi.NewInlineQueryResultPhotoWithThumb(
+			id,
+			release.CoverUrl.Value,
+			release.CoverUrl.Value,
+		)
+		result.Title = title
+		result.Description = description
+		result.Caption = GenerateCaption(lang, release)
+		result.ParseMode = tgbotapi.ModeHTML
+		return result
+	}
+
+	result := tgbotapi.NewInlineQueryResultArticleHTML(id, title, GenerateCaption(lang, release))
+	result.Description = description
+	return result
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestGenerateInlineAnswer(t *testing.T) {
+	t.Run("error gives empty answer", func(t *testing.T) {
+		answer := GenerateInlineAnswer("42", i18n.En, nil, 0, errors.New("database is locked"))
+		assert.Equal(t, "42", answer.InlineQueryID)
+		assert.NotNil(t, answer.Results)
+		assert.Empty(t, answer.Results)
+		assert.Equal(t, InlineErrorCacheSeconds, answer.CacheTime)
+	})
+
+	t.Run("full page has next offset", func(t *testing.T) {
+		releases := make([]models.Release, InlineResultsLimit)
+		for i := range releases {
+			releases[i] = models.Release{Id: i + 1, Title: "GNX", OutDate: types.NewCustomDate(2024, time.November, 22)}
+		}
+		answer := GenerateInlineAnswer("42", i18n.En, releases, 20, nil)
+		assert.Len(t, answer.Results, InlineResultsLimit)
+		assert.Equal(t, "40", answer.NextOffset)
+		assert.Equal(t, InlineCacheSeconds, answer.CacheTime)
+	})
+}
@@ -2,27 +2,41 @@ package bot
 
 import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/i18n"
 )
 
-var mainKeybordButtons = [][]tgbotapi.KeyboardButton{
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(TodayButtonText),
-		tgbotapi.NewKeyboardButton(TodayReleasesButtonText),
-		tgbotapi.NewKeyboardButton(MonthReleasesButtonText),
-	),
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(SubscribeButtonText),
-		tgbotapi.NewKeyboardButton(UnsubscribeButtonText),
-		tgbotapi.NewKeyboardButton(CheckSubscribeButtonText),
-	),
+func mainKeyboardButtons(lang i18n.Lang) [][]tgbotapi.KeyboardButton {
+	return [][]tgbotapi.KeyboardButton{
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(T(lang, TodayButtonText)),
+			tgbotapi.NewKeyboardButton(T(lang, TodayReleasesButtonText)),
+			tgbotapi.NewKeyboardButton(T(lang, MonthReleasesButtonText)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(T(lang, UpcomingReleasesButtonText)),
+			tgbotapi.NewKeyboardButton(T(lang, YearReleasesByMonthButtonText)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(T(lang, SubscriptionsButtonText)),
+			tgbotapi.NewKeyboardButton(T(lang, LanguageButtonText)),
+		),
+	}
 }
 
-var adminKeyboardButtons = append(mainKeybordButtons, tgbotapi.NewKeyboardButtonRow(
-	tgbotapi.NewKeyboardButton(RefreshReleasesButtonText),
-	tgbotapi.NewKeyboardButton(TestButtonText),
-))
+func MainKeyboard(lang i18n.Lang) tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(mainKeyboardButtons(lang)...)
+}
 
-var (
-	mainKeyboard  = tgbotapi.NewReplyKeyboard(mainKeybordButtons...)
-	adminKeyboard = tgbotapi.NewReplyKeyboard(adminKeyboardButtons...)
-)
+func AdminKeyboard(lang i18n.Lang) tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(append(
+		mainKeyboardButtons(lang),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(T(lang, RefreshReleasesButtonText)),
+			tgbotapi.NewKeyboardButton(T(lang, TestButtonText)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(T(lang, BroadcastButtonText)),
+		),
+	)...)
+}
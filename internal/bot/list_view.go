@@ -0,0 +1,401 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+type ListKind byte
+
+const (
+	MonthList    ListKind = 'm'
+	DayList      ListKind = 'd'
+	UpcomingList ListKind = 'u'
+	SearchList   ListKind = 's'
+	LibraryList  ListKind = 'l'
+)
+
+// ListCallbackVersion меняется при несовместимом изменении формата,
+// кнопки старых сообщений с другой версией считаются устаревшими.
+const ListCallbackVersion = 1
+
+var ErrOutdatedListCallback = errors.New("outdated list callback data")
+
+// ListView - все, что нужно, чтобы заново показать страницу списка релизов.
+// Представление целиком кладется в callback data кнопок, поэтому любое сообщение
+// со списком листается независимо от остальных и без состояния на сервере.
+type ListView struct {
+	Kind   ListKind
+	Year   int
+	Month  time.Month
+	Day    int
+	Days   int
+	Query  string
+	ByDate bool // личный список по дате выхода, а не в порядке сохранения
+	Filter models.ReleaseType
+	Page   int
+}
+
+func NewMonthView(year int, month time.Month, filter models.ReleaseType) ListView {
+	return ListView{Kind: MonthList, Year: year, Month: month, Filter: filter, Page: 1}
+}
+
+func NewDayView(date time.Time, filter models.ReleaseType) ListView {
+	return ListView{
+		Kind:   DayList,
+		Year:   date.Year(),
+		Month:  date.Month(),
+		Day:    date.Day(),
+		Filter: filter,
+		Page:   1,
+	}
+}
+
+func NewUpcomingView(days int) ListView {
+	return ListView{Kind: UpcomingList, Days: days, Page: 1}
+}
+
+// SearchQueryMaxLen - длина запроса поиска в байтах, с которой callback data любой кнопки
+// результатов, включая карточку релиза "rel:<id>:<список>", не превышает CallbackDataMaxLen.
+// Запас рассчитан на id релиза до 9 символов и номер страницы до 4 цифр.
+const SearchQueryMaxLen = CallbackDataMaxLen -
+	len(ReleaseCallbackPrefix+"-12345678:"+ListCallbackPrefix+"1:s:0.9999:")
+
+// ShortenSearchQuery обрезает запрос до SearchQueryMaxLen еще до первого поиска,
+// чтобы все страницы и возврат из карточки искали одно и то же.
+func ShortenSearchQuery(query string) string {
+	return strings.TrimSpace(truncateBytes(query, SearchQueryMaxLen))
+}
+
+func NewSearchView(query string) ListView {
+	return ListView{Kind: SearchList, Query: query, Page: 1}
+}
+
+// NewLibraryView - личный список /mylist, чей именно - определяет тот, кто нажал кнопку.
+func NewLibraryView(byDate bool) ListView {
+	return ListView{Kind: LibraryList, ByDate: byDate, Page: 1}
+}
+
+func (v ListView) WithPage(page int) ListView {
+	v.Page = page
+	return v
+}
+
+// WithFilter меняет фильтр и возвращает к первой странице.
+func (v ListView) WithFilter(filter models.ReleaseType) ListView {
+	v.Filter = filter
+	v.Page = 1
+	return v
+}
+
+// HasTypeFilter - списки, которые умеют фильтровать релизы по типу.
+func (v ListView) HasTypeFilter() bool {
+	return v.Kind == MonthList || v.Kind == DayList
+}
+
+// Encode кодирует представление в "ls1:<вид>:<числа через точку>[:<запрос>]",
+// например "ls1:m:2024.3.0.2" - вторая страница релизов за март 2024 без фильтра.
+// Последние два числа - фильтр и страница. Запрос поиска уже укорочен ShortenSearchQuery.
+func (v ListView) Encode() string {
+	var params []int
+	switch v.Kind {
+	case MonthList:
+		params = []int{v.Year, int(v.Month)}
+	case DayList:
+		params = []int{v.Year, int(v.Month), v.Day}
+	case UpcomingList:
+		params = []int{v.Days}
+	case LibraryList:
+		params = []int{0}
+		if v.ByDate {
+			params[0] = 1
+		}
+	}
+	params = append(params, int(v.Filter), v.Page)
+
+	strParams := make([]string, 0, len(params))
+	for _, param := range params {
+		strParams = append(strParams, strconv.Itoa(param))
+	}
+
+	data := fmt.Sprintf(
+		"%s%d:%c:%s",
+		ListCallbackPrefix,
+		ListCallbackVersion,
+		v.Kind,
+		strings.Join(strParams, "."),
+	)
+	if v.Kind == SearchList {
+		data += ":" + v.Query
+	}
+
+	return TruncateCallbackData(data)
+}
+
+func DecodeListView(data string) (ListView, error) {
+	parts := strings.SplitN(strings.TrimPrefix(data, ListCallbackPrefix), ":", 4)
+	if len(parts) < 3 {
+		return ListView{}, fmt.Errorf("invalid list callback data: %s", data)
+	}
+	if parts[0] != strconv.Itoa(ListCallbackVersion) {
+		return ListView{}, ErrOutdatedListCallback
+	}
+	if len(parts[1]) != 1 {
+		return ListView{}, fmt.Errorf("invalid list kind in callback data: %s", data)
+	}
+
+	params := make([]int, 0, 5)
+	for _, strParam := range strings.Split(parts[2], ".") {
+		param, err := strconv.Atoi(strParam)
+		if err != nil {
+			return ListView{}, fmt.Errorf("invalid list callback data %s: %w", data, err)
+		}
+		params = append(params, param)
+	}
+
+	view := ListView{Kind: ListKind(parts[1][0])}
+	var ok bool
+	switch view.Kind {
+	case MonthList:
+		ok = len(params) == 4
+		if ok {
+			view.Year, view.Month = params[0], time.Month(params[1])
+			ok = view.Month >= time.January && view.Month <= time.December
+		}
+	case DayList:
+		ok = len(params) == 5
+		if ok {
+			view.Year, view.Month, view.Day = params[0], time.Month(params[1]), params[2]
+			date := time.Date(view.Year, view.Month, view.Day, 0, 0, 0, 0, time.UTC)
+			ok = date.Month() == view.Month && date.Day() == view.Day
+		}
+	case UpcomingList:
+		ok = len(params) == 3
+		if ok {
+			view.Days = params[0]
+			ok = slices.Contains(UpcomingPeriods, view.Days)
+		}
+	case SearchList:
+		ok = len(params) == 2 && len(parts) == 4
+		if ok {
+			view.Query = parts[3]
+		}
+	case LibraryList:
+		ok = len(params) == 3 && (params[0] == 0 || params[0] == 1)
+		if ok {
+			view.ByDate = params[0] == 1
+		}
+	}
+	if !ok {
+		return ListView{}, fmt.Errorf("invalid list callback data: %s", data)
+	}
+
+	view.Filter = models.ReleaseType(params[len(params)-2])
+	view.Page = params[len(params)-1]
+	if !slices.Contains(ReleaseTypeFilters, view.Filter) || view.Page < 1 {
+		return ListView{}, fmt.Errorf("invalid list callback data: %s", data)
+	}
+
+	return view, nil
+}
+
+// GetListReleases достает страницу релизов для представления.
+// Предстоящие релизы считаются от now - текущего времени пользователя или чата,
+// личный список берется у пользователя userId.
+func (b *TGBot) GetListReleases(
+	now time.Time,
+	userId int64,
+	view ListView,
+) ([]models.Release, error) {
+	offset := (view.Page - 1) * StandardReleasesLimit
+	switch view.Kind {
+	case MonthList:
+		return b.Service.GetMonthReleases(
+			view.Year, view.Month,
+			view.Filter,
+			StandardReleasesLimit,
+			offset,
+		)
+	case DayList:
+		return b.Service.GetReleasesByDay(
+			view.Year, view.Month, view.Day,
+			view.Filter,
+			StandardReleasesLimit,
+			offset,
+		)
+	case UpcomingList:
+		return b.Service.GetReleasesByPeriod(
+			now,
+			now.AddDate(0, 0, view.Days-1),
+			StandardReleasesLimit,
+			offset,
+		)
+	case SearchList:
+		return b.Service.SearchReleases(view.Query, StandardReleasesLimit, offset)
+	case LibraryList:
+		return b.Service.GetSavedReleases(userId, view.ByDate, StandardReleasesLimit, offset)
+	}
+
+	return nil, fmt.Errorf("unknown list kind %c", view.Kind)
+}
+
+func (b *TGBot) GenerateListMedia(
+	lang i18n.Lang,
+	view ListView,
+	releases []models.Release,
+) tgbotapi.InputMediaPhoto {
+	media := b.GenerateReleasesEditMessage(lang, releases)
+	switch view.Kind {
+	case MonthList:
+		media.Caption = fmt.Sprintf(
+			"<b>%s %d</b>\n\n%s",
+			i18n.MonthName(lang, view.Month), view.Year,
+			media.Caption,
+		)
+	case DayList:
+		date := time.Date(view.Year, view.Month, view.Day, 0, 0, 0, 0, time.UTC)
+		media.Caption = fmt.Sprintf("<b>%s</b>\n\n%s", i18n.FormatDate(lang, date), media.Caption)
+	case UpcomingList:
+		media.Caption = GenerateUpcomingCaption(lang, view.Days, releases)
+		if len(releases) == 0 {
+			media.Caption = T(lang, NoUpcomingReleasesMessage, view.Days)
+		}
+	case LibraryList:
+		media.Caption = fmt.Sprintf("<b>%s</b>\n\n%s", T(lang, LibraryMessage), media.Caption)
+		if len(releases) == 0 && view.Page == 1 {
+			media.Caption = T(lang, EmptyLibraryMessage)
+		}
+	}
+
+	return media
+}
+
+// GenerateListKeyboard собирает клавиатуру страницы: кнопки карточек релизов,
+// пагинацию, фильтр по типу и навигацию, которая есть у конкретного вида списка.
+func GenerateListKeyboard(
+	lang i18n.Lang,
+	view ListView,
+	releases []models.Release,
+) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, 6)
+
+	if view.Kind == UpcomingList {
+		periodButtons := make([]tgbotapi.InlineKeyboardButton, 0, len(UpcomingPeriods))
+		for _, period := range UpcomingPeriods {
+			text := T(lang, UpcomingDaysButtonText, period)
+			if period == view.Days {
+				text = fmt.Sprintf(SelectedButtonText, text)
+			}
+			periodButtons = append(
+				periodButtons,
+				tgbotapi.NewInlineKeyboardButtonData(text, NewUpcomingView(period).Encode()),
+			)
+		}
+		rows = append(rows, periodButtons)
+	}
+
+	if view.Kind == LibraryList {
+		orderButtons := make([]tgbotapi.InlineKeyboardButton, 0, 2)
+		for _, byDate := range []bool{false, true} {
+			text := T(lang, SavedOrderButtonText)
+			if byDate {
+				text = T(lang, ReleaseDateOrderButtonText)
+			}
+			if byDate == view.ByDate {
+				text = fmt.Sprintf(SelectedButtonText, text)
+			}
+			orderButtons = append(
+				orderButtons,
+				tgbotapi.NewInlineKeyboardButtonData(text, NewLibraryView(byDate).Encode()),
+			)
+		}
+		rows = append(rows, orderButtons)
+	}
+
+	rows = append(rows, GenerateReleasesButtonsRows(releases, view.Encode())...)
+
+	if len(releases) != 0 || view.Page > 1 {
+		rows = append(rows, GeneratePageRow(
+			view.Page,
+			len(releases) == StandardReleasesLimit,
+			view.WithPage(view.Page-1).Encode(),
+			view.WithPage(view.Page+1).Encode(),
+		))
+	}
+
+	if view.HasTypeFilter() {
+		filterButtons := make([]tgbotapi.InlineKeyboardButton, 0, len(ReleaseTypeFilters))
+		for _, releaseType := range ReleaseTypeFilters {
+			text := T(lang, ReleaseTypeFiltersText[releaseType])
+			if releaseType == view.Filter {
+				text = fmt.Sprintf(SelectedButtonText, text)
+			}
+			filterButtons = append(
+				filterButtons,
+				tgbotapi.NewInlineKeyboardButtonData(text, view.WithFilter(releaseType).Encode()),
+			)
+		}
+		rows = append(rows, filterButtons)
+	}
+
+	if view.Kind == MonthList {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				T(lang, BackToMonthsButtonText),
+				GenerateCalendarCallbackData(view.Year),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				T(lang, BackToYearsButtonText),
+				CalendarCallbackPrefix,
+			),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// SendListPage отправляет новое сообщение со страницей списка.
+func (b *TGBot) SendListPage(
+	chatId int64,
+	lang i18n.Lang,
+	view ListView,
+	releases []models.Release,
+) error {
+	media := b.GenerateListMedia(lang, view, releases)
+	msg := tgbotapi.NewPhoto(chatId, media.Media)
+	msg.Caption = media.Caption
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = GenerateListKeyboard(lang, view, releases)
+	_, err := b.Send(msg)
+	return err
+}
+
+// EditListPage показывает страницу списка в уже отправленном сообщении.
+func (b *TGBot) EditListPage(
+	msg *tgbotapi.Message,
+	lang i18n.Lang,
+	view ListView,
+	releases []models.Release,
+) error {
+	inlineKeyboard := GenerateListKeyboard(lang, view, releases)
+	msgEdit := tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{
+			ChatID:      msg.Chat.ID,
+			MessageID:   msg.MessageID,
+			ReplyMarkup: &inlineKeyboard,
+		},
+		Media: b.GenerateListMedia(lang, view, releases),
+	}
+
+	_, err := b.Send(msgEdit)
+	return err
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+func TestListView(t *testing.T) {
+	day := time.Date(2024, time.February, 29, 15, 0, 0, 0, time.UTC)
+	views := []ListView{
+		NewMonthView(2024, time.December, models.Single).WithPage(3),
+		NewDayView(day, models.Album),
+		NewUpcomingView(30).WithPage(2),
+		NewSearchView("tyler: the creator"),
+		NewLibraryView(true).WithPage(2),
+	}
+
+	for _, view := range views {
+		t.Run("round trip "+view.Encode(), func(t *testing.T) {
+			got, err := DecodeListView(view.Encode())
+			assert.NoError(t, err)
+			assert.Equal(t, view, got)
+		})
+	}
+
+	t.Run("filter change resets page", func(t *testing.T) {
+		view := NewMonthView(2024, time.March, models.AnyType).WithPage(4).WithFilter(models.Album)
+		assert.Equal(t, "ls1:m:2024.3.1.1", view.Encode())
+	})
+
+	t.Run("every button fits in callback data", func(t *testing.T) {
+		view := NewSearchView(strings.Repeat("я", CallbackDataMaxLen)).WithPage(999)
+		keyboard := GenerateListKeyboard(i18n.Ru, view, make([]models.Release, StandardReleasesLimit))
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				assert.LessOrEqual(t, len(*button.CallbackData), CallbackDataMaxLen)
+			}
+		}
+	})
+
+	t.Run("long query is the same on every page and after release card", func(t *testing.T) {
+		query := ShortenSearchQuery(strings.Repeat("я", CallbackDataMaxLen))
+		assert.LessOrEqual(t, len(query), SearchQueryMaxLen)
+
+		view := NewSearchView(query).WithPage(999)
+		decoded, err := DecodeListView(view.Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, query, decoded.Query)
+
+		releaseData := GenerateReleaseCallbackData(-12345678, view.Encode())
+		_, backData, err := ParseReleaseCallbackData(releaseData)
+		assert.NoError(t, err)
+		decoded, err = DecodeListView(backData)
+		assert.NoError(t, err)
+		assert.Equal(t, view, decoded)
+	})
+
+	invalid := []struct {
+		name string
+		data string
+	}{
+		{"another version", "ls0:m:2024.3.0.1"},
+		{"unknown kind", "ls1:x:2024.3.0.1"},
+		{"wrong month", "ls1:m:2024.13.0.1"},
+		{"wrong day", "ls1:d:2023.2.29.0.1"},
+		{"unknown period", "ls1:u:5.0.1"},
+		{"unknown filter", "ls1:m:2024.3.7.1"},
+		{"zero page", "ls1:m:2024.3.0.0"},
+		{"search without query", "ls1:s:0.1"},
+		{"unknown library order", "ls1:l:2.0.1"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeListView(tc.data)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("outdated version", func(t *testing.T) {
+		_, err := DecodeListView("ls0:m:2024.3.0.1")
+		assert.ErrorIs(t, err, ErrOutdatedListCallback)
+	})
+}
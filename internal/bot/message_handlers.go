@@ -4,20 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"strconv"
-	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"hip-hop-geek/internal/db/sqlite"
 	"hip-hop-geek/internal/fetcher"
+	"hip-hop-geek/internal/i18n"
 	"hip-hop-geek/internal/models"
 )
 
 const (
 	newReleasesPicUrl = "https://assets.sentaifilmworks.com/category-defaults/NewReleases.jpg"
+	CollageFileName   = "releases.jpg"
 	TextParseMode     = "HTML"
 
 	NoOffset = 0
@@ -26,260 +25,195 @@ const (
 	StandardReleasesLimit = 10
 )
 
-func (b *TGBot) ReleasesHandler(upd tgbotapi.Update, user *models.User) {
+func (b *TGBot) ReleasesHandler(upd tgbotapi.Update, user *models.User) error {
 	log.Println("processing /releases command")
-	chatId := upd.FromChat().ID
-	now := time.Now().UTC()
-	var needNewMessage bool
+	now := UserNow(user)
+	return b.sendList(upd.FromChat().ID, user, NewMonthView(now.Year(), now.Month(), user.ReleaseTypeFilter))
+}
 
-	if user.ReleasesPageCount == 0 {
-		needNewMessage = true
-	}
-	pageCount := user.ReleasesPageCount
-
-	if needNewMessage {
-		pageCount = 1
-		releases := b.Service.GetMonthReleases(
-			now.Year(),
-			now.Month(),
-			StandardReleasesLimit,
-			NoOffset,
-		)
-		msg := GenerateReleasesMessage(chatId, models.ReleasesMessage, pageCount, releases)
-
-		doneMsg, err := b.Send(msg)
-		if err != nil {
-			log.Fatalf("error while sending photo message: %s", err)
-		}
-		b.Service.SetUserState(user.Id, models.ReleasesMessage, doneMsg.MessageID, pageCount)
-		return
-	} else {
-		offset := (pageCount - 1) * StandardReleasesLimit
-		releases := b.Service.GetMonthReleases(
-			now.Year(), now.Month(),
-			StandardReleasesLimit,
-			offset,
-		)
-		deleteMsg := tgbotapi.NewDeleteMessage(chatId, int(user.ReleasesMessageId))
-		b.Send(deleteMsg)
-		msg := GenerateReleasesMessage(chatId, models.ReleasesMessage, pageCount, releases)
-
-		doneMsg, err := b.Send(msg)
-		if err != nil {
-			log.Fatalf("error while sending photo message: %s", err)
-		}
-		b.Service.SetUserState(user.Id, models.ReleasesMessage, doneMsg.MessageID, pageCount)
-		return
+func (b *TGBot) TodayEventHandler(chatId int64, lang i18n.Lang, date time.Time) {
+	events, err := b.Service.GetEventsByDate(date)
+	if err := b.EventsHandler(chatId, lang, events, err); err != nil {
+		log.Printf("error while sending today events to %d: %s", chatId, err)
 	}
 }
 
-func (b *TGBot) TodayEventHandler(chatId int64) {
-	msg := tgbotapi.NewPhoto(chatId, nil)
-	events, err := b.Service.GetTodayEvents()
+// EventsHandler отправляет события из истории, ошибку получения событий показывает
+// пользователю. Возвращает ошибку отправки, чтобы рассылка могла ее учесть.
+func (b *TGBot) EventsHandler(
+	chatId int64,
+	lang i18n.Lang,
+	events []*models.TodayPost,
+	err error,
+) error {
 	if err != nil {
 		log.Printf("error while getting today events: %s", err)
+		text := T(lang, ErrorUserMessage)
 		if errors.Is(err, fetcher.ErrPostsNotFound) {
-			b.mustSend(tgbotapi.NewMessage(int64(chatId), ErrorPostsNotFound))
-			return
+			text = T(lang, ErrorPostsNotFound)
 		}
-		b.mustSend(tgbotapi.NewMessage(int64(chatId), ErrorUserMessage))
-		return
+		_, err = b.Send(tgbotapi.NewMessage(chatId, text))
+		return err
 	}
 
+	msg := tgbotapi.NewPhoto(chatId, nil)
 	for _, event := range events {
 		msg.File = tgbotapi.FileURL(event.Url)
-		msg.Caption = fmt.Sprintf("%s\n%s", "Today in Hip Hop History:", event.Text)
-
-		b.mustSend(msg)
+		msg.Caption = T(lang, TodayInHistoryMessage, event.Text)
 
+		if _, err := b.Send(msg); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func (b *TGBot) echoMessage(upd tgbotapi.Update) {
 	msg := tgbotapi.NewMessage(upd.Message.Chat.ID, upd.Message.Text)
-	b.mustSend(msg)
+	b.trySend(msg)
 }
 
-func (b *TGBot) UnsubscribeHandler(user *models.User) {
-	b.Service.SetTodaySubscribe(user.Id, false)
-	msg := tgbotapi.NewMessage(int64(user.Id), SuccessUnsubscribeMessage)
-	b.mustSend(msg)
+func (b *TGBot) SubscriptionsHandler(user *models.User) error {
+	lang := UserLang(user)
+	kinds, err := b.Service.GetUserSubscriptions(user.Id)
+	if err != nil {
+		return fmt.Errorf("error while getting user subscriptions: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(
+		user.Id,
+		T(lang, SubscriptionsMessage, UserDigestTime(user), UserLocation(user)),
+	)
+	msg.ReplyMarkup = GenerateSubscriptionsKeyboard(lang, kinds)
+	b.trySend(msg)
+
+	return nil
 }
 
-func (b *TGBot) SubscribeHandler(user *models.User) {
-	b.Service.SetTodaySubscribe(user.Id, true)
-	msg := tgbotapi.NewMessage(int64(user.Id), SuccessSubscribeMessage)
-	b.mustSend(msg)
+func (b *TGBot) LanguageHandler(user *models.User) error {
+	lang := UserLang(user)
+	msg := tgbotapi.NewMessage(user.Id, T(lang, ChooseLanguageMessage))
+	msg.ReplyMarkup = GenerateLanguageKeyboard(lang)
+	b.trySend(msg)
+
+	return nil
 }
 
-func (b *TGBot) CheckSubscribeHandler(user *models.User) {
-	user, _ = b.Service.GetUserByUsername(user.Username)
-	subscribeStatus := "не активна"
-	if user.IsTodaySubscribe {
-		subscribeStatus = "активна"
+// TodayReleasesHandler отправляет релизы за date - сегодняшний день пользователя.
+func (b *TGBot) TodayReleasesHandler(user *models.User, date time.Time) {
+	err := b.sendTodayReleases(user.Id, UserLang(user), user.ReleaseTypeFilter, date)
+	if err != nil {
+		log.Printf("error while sending today releases to %d: %s", user.Id, err)
 	}
-	msg := tgbotapi.NewMessage(
-		int64(user.Id),
-		fmt.Sprintf(CheckSubscribeMessage, subscribeStatus),
-	)
-	b.mustSend(msg)
 }
 
-func (b *TGBot) SendTodayEventToSubscribers() {
-	allSubs, err := b.Service.GetAllSubscribers()
+func (b *TGBot) sendTodayReleases(
+	chatId int64,
+	lang i18n.Lang,
+	filter models.ReleaseType,
+	date time.Time,
+) error {
+	log.Println("processing today releases")
+	view := NewDayView(date, filter)
+	releases, err := b.GetListReleases(date, chatId, view)
 	if err != nil {
-		if errors.Is(err, sqlite.ErrUserNotFound) {
-			log.Println("subscribers not found")
-		} else {
-			adminId, _ := strconv.Atoi(os.Getenv("ADMIN_ID"))
-			msg := tgbotapi.NewMessage(int64(adminId), fmt.Sprintf(ErrorAdminMessage, err))
-			b.mustSend(msg)
-		}
-		return
+		return fmt.Errorf("error while getting today releases: %w", err)
 	}
-
-	var wg sync.WaitGroup
-	wg.Add(len(allSubs))
-	log.Println("sending today event to all subscribers")
-	for _, subscriber := range allSubs {
-		go func(subscriber *models.User) {
-			defer wg.Done()
-			b.TodayEventHandler(subscriber.Id)
-		}(subscriber)
+	if len(releases) == 0 && view.Filter == models.AnyType {
+		_, err = b.Send(tgbotapi.NewMessage(chatId, T(lang, NoTodayReleasesMessage)))
+		return err
 	}
 
-	wg.Wait()
+	return b.SendListPage(chatId, lang, view, releases)
+}
+
+func (b *TGBot) RefreshReleasesHandler(user *models.User, years []int) {
+	lang := UserLang(user)
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, RefreshReleasesStartMessageText, JoinYears(years))))
+	b.Updater.RefreshReleases(years)
+	b.trySend(tgbotapi.NewMessage(user.Id, T(lang, RefreshReleasesEndMessageText)))
 }
 
-func (b *TGBot) SendTodayReleasesToSubscribers() {
-	log.Println("sending today releases to subscribers")
-	allSubs, err := b.Service.GetAllSubscribers()
+func (b *TGBot) YearReleasesHandler(user *models.User) error {
+	lang := UserLang(user)
+	years, err := b.Service.GetReleaseYears()
 	if err != nil {
-		if errors.Is(err, sqlite.ErrUserNotFound) {
-			log.Println("subscribers not found")
-		} else {
-			adminId, _ := strconv.Atoi(os.Getenv("ADMIN_ID"))
-			msg := tgbotapi.NewMessage(int64(adminId), fmt.Sprintf(ErrorAdminMessage, err))
-			b.mustSend(msg)
+		if errors.Is(err, sqlite.ErrReleasesNotFound) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ReleasesNotFoundMessage)))
+			return nil
 		}
-		return
+		return fmt.Errorf("error while getting release years: %w", err)
 	}
-	now := time.Now().UTC()
-	releases := b.Service.GetReleasesByDay(now.Year(), now.Month(), now.Day(), NoLimit, NoOffset)
 
-	// Если нет релизов то просто ничего не отправляем
-	if len(releases) == 0 {
-		log.Println("today releases not found, skip...")
-		return
-	}
+	msg := tgbotapi.NewPhoto(user.Id, tgbotapi.FileURL(newReleasesPicUrl))
+	msg.Caption = T(lang, ChooseYearMessage)
+	msg.ReplyMarkup = GenerateYearsKeyboard(years)
+	b.trySend(msg)
 
-	var wg sync.WaitGroup
-	wg.Add(len(allSubs))
-	log.Println("sending today releases to all subscribers")
-	for _, subscriber := range allSubs {
-		go func(subscriber *models.User, releases []models.Release) {
-			defer wg.Done()
-			b.TodayReleasesHandler(subscriber, releases)
-		}(subscriber, releases)
-	}
+	return nil
+}
 
-	wg.Wait()
+func (b *TGBot) UpcomingReleasesHandler(user *models.User) error {
+	return b.sendList(user.Id, user, NewUpcomingView(UpcomingPeriods[0]))
 }
 
-func (b *TGBot) TodayReleasesHandler(user *models.User, releases []models.Release) {
-	log.Println("processing today releases")
+// MyListHandler показывает личный список сохраненных релизов, последние сохраненные первыми.
+func (b *TGBot) MyListHandler(user *models.User) error {
+	return b.sendList(user.Id, user, NewLibraryView(false))
+}
 
-	now := time.Now().UTC()
-	var needNewMessage bool
+// sendList отправляет первую страницу списка новым сообщением.
+func (b *TGBot) sendList(chatId int64, user *models.User, view ListView) error {
+	releases, err := b.GetListReleases(UserNow(user), user.Id, view)
+	if err != nil {
+		return fmt.Errorf("error while getting releases for list %s: %w", view.Encode(), err)
+	}
 
-	if user.TodayReleasesPageCount == 0 {
-		needNewMessage = true
+	if err := b.SendListPage(chatId, UserLang(user), view, releases); err != nil {
+		log.Printf("error while sending list %s to %d: %s", view.Encode(), chatId, err)
 	}
-	pageCount := user.TodayReleasesPageCount
-
-	if needNewMessage {
-		pageCount = 1
-		releases := b.Service.GetReleasesByDay(
-			now.Year(),
-			now.Month(),
-			now.Day(),
-			StandardReleasesLimit,
-			NoOffset,
-		)
-		if len(releases) == 0 {
-			b.Send(tgbotapi.NewMessage(user.Id, "No today releases :("))
-			return
-		}
-		msg := GenerateReleasesMessage(user.Id, models.TodayReleasesMessage, pageCount, releases)
 
-		doneMsg, err := b.Send(msg)
-		if err != nil {
-			log.Fatalf("error while sending photo message: %s", err)
-		}
-		b.Service.SetUserState(user.Id, models.TodayReleasesMessage, doneMsg.MessageID, pageCount)
-		// b.SetUserState(chatId, int64(doneMsg.MessageID), pageCount)
-		return
-	} else {
-		releases := b.Service.GetReleasesByDay(
-			now.Year(), now.Month(), now.Day(),
-			StandardReleasesLimit,
-			(pageCount-1)*StandardReleasesLimit,
-		)
-		if len(releases) == 0 {
-			b.Send(tgbotapi.NewMessage(user.Id, "No today releases :("))
-			return
-		}
-		deleteMsg := tgbotapi.NewDeleteMessage(user.Id, int(user.TodayReleasesMessageId))
-		b.Send(deleteMsg)
-		msg := GenerateReleasesMessage(user.Id, models.TodayReleasesMessage, pageCount, releases)
+	return nil
+}
 
-		doneMsg, err := b.Send(msg)
-		if err != nil {
-			log.Fatalf("error while sending photo message: %s", err)
-		}
-		b.Service.SetUserState(user.Id, models.TodayReleasesMessage, doneMsg.MessageID, pageCount)
-		return
+func (b *TGBot) TodayButtonHandler(user *models.User) error {
+	b.TodayEventHandler(user.Id, UserLang(user), UserNow(user))
+
+	return nil
+}
+
+func (b *TGBot) TodayReleasesButtonHandler(user *models.User) error {
+	now := UserNow(user)
+	releases, err := b.Service.GetReleasesByDay(
+		now.Year(), now.Month(), now.Day(),
+		models.AnyType,
+		NoLimit, NoOffset,
+	)
+	if err != nil {
+		return err
+	}
+	if releases == nil {
+		b.trySend(tgbotapi.NewMessage(user.Id, T(UserLang(user), NoTodayReleasesMessage)))
+		return nil
 	}
-	// for _, chunk := range chunkBy(releases, 10) {
-	// 	media := make([]tgbotapi.InputMediaPhoto, 0)
-	// 	caption := make([]string, 0)
-	// 	for _, release := range chunk {
-	//
-	// 		imgCaption := GenerateCaptionForTodayRelease(release)
-	// 		caption = append(caption, imgCaption)
-	//
-	// 		if release.CoverUrl.IsValid {
-	// 			photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(release.CoverUrl.Value))
-	// 			photo.Caption = imgCaption
-	// 			photo.ParseMode = TextParseMode
-	// 			media = append(media, photo)
-	// 		}
-	// 	}
-	//
-	// 	if len(media) != 0 {
-	// 		media[0].Caption = fmt.Sprintf("Today releases:\n\n%s", strings.Join(caption, "\n\n"))
-	// 		mediaInterfaces := make([]interface{}, len(media))
-	// 		for i, photo := range media {
-	// 			mediaInterfaces[i] = photo
-	// 		}
-	// 		mg := tgbotapi.NewMediaGroup(chatId, mediaInterfaces)
-	// 		if _, err := b.SendMediaGroup(mg); err != nil {
-	// 			log.Printf("error while sending media group: %s", err)
-	// 		}
-	//
-	// 	} else {
-	// 		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(newReleasesPicUrl))
-	// 		photo.ParseMode = TextParseMode
-	// 		photo.Caption = fmt.Sprintf("Today releases:\n\n%s", strings.Join(caption, "\n\n"))
-	// 		msg := tgbotapi.NewMediaGroup(chatId, []interface{}{photo})
-	// 		if _, err := b.SendMediaGroup(msg); err != nil {
-	// 			log.Printf("error while sending media group in today releases: %s", err)
-	// 		}
-	// 	}
-	//
-	// }
+	b.TodayReleasesHandler(user, now)
+
+	return nil
 }
 
-func (b *TGBot) RefreshReleasesHandler(years []int) {
-	b.Updater.RefreshReleases(years)
+func (b *TGBot) TestButtonHandler(user *models.User) error {
+	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("test", "test"),
+		),
+	)
+
+	photoMsg := tgbotapi.NewPhoto(user.Id, tgbotapi.FileURL(newReleasesPicUrl))
+	photoMsg.Caption = "Test Caption"
+	photoMsg.ReplyMarkup = inlineKeyboard
+
+	b.Send(photoMsg)
+
+	return nil
 }
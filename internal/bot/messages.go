@@ -1,48 +1,261 @@
 package bot
 
+import (
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// Ключи каталога сообщений, тексты на каждом языке лежат в messages_<язык>.go.
 const (
 	// MESSAGES
-	ErrorAdminMessage               = "Произошла ошибка: %s"
-	ErrorUserMessage                = "Во время обработки сообщения произошла ошибка на сервере."
-	ErrorPostsNotFound              = "Сегодня в хип хопе не происходило никаких событий"
-	SuccessSubscribeMessage         = "Вы успешно подписались на ежедневную рассылку истории Хип Хопа."
-	SuccessUnsubscribeMessage       = "Вы успешно отписались от ежедневной рассылки  истории Хип Хопа."
-	CheckSubscribeMessage           = "Ваша подписка %s"
-	ReleasesNotFoundMessage         = "Релизы не найдены"
-	StartCommandMessageText         = "Привет! Я бот - Хип Хоп гик, который знает обо всех релизах и событиях в жизни хип хопа. Отправляю тебе клавиатуру с нужными командами"
-	RefreshReleasesStartMessageText = "Запускаю обновление релизов"
-	RefreshReleasesEndMessageText   = "Обновление релизов завершено"
+	ErrorAdminMessage               i18n.Key = "error_admin"
+	UpdateErrorAdminMessage         i18n.Key = "update_error_admin"
+	UpdaterErrorAdminMessage        i18n.Key = "updater_error_admin"
+	ErrorUserMessage                i18n.Key = "error_user"
+	ErrorPostsNotFound              i18n.Key = "posts_not_found"
+	TodayInHistoryMessage           i18n.Key = "today_in_history"
+	SubscriptionsMessage            i18n.Key = "subscriptions"
+	SubscribedMessage               i18n.Key = "subscribed"
+	UnsubscribedMessage             i18n.Key = "unsubscribed"
+	ReleasesNotFoundMessage         i18n.Key = "releases_not_found"
+	NoTodayReleasesMessage          i18n.Key = "no_today_releases"
+	StartCommandMessageText         i18n.Key = "start"
+	RefreshReleasesStartMessageText i18n.Key = "refresh_releases_start"
+	RefreshReleasesEndMessageText   i18n.Key = "refresh_releases_end"
+	FollowUsageMessage              i18n.Key = "follow_usage"
+	ArtistNotFoundMessage           i18n.Key = "artist_not_found"
+	SuccessFollowMessage            i18n.Key = "success_follow"
+	AlreadyFollowingMessage         i18n.Key = "already_following"
+	SuccessUnfollowMessage          i18n.Key = "success_unfollow"
+	FollowedArtistsMessage          i18n.Key = "followed_artists"
+	NoFollowedArtistsMessage        i18n.Key = "no_followed_artists"
+	NewFollowedReleaseMessage       i18n.Key = "new_followed_release"
+	SearchUsageMessage              i18n.Key = "search_usage"
+	ChooseYearMessage               i18n.Key = "choose_year"
+	ChooseMonthMessage              i18n.Key = "choose_month"
+	UpcomingReleasesMessage         i18n.Key = "upcoming_releases"
+	NoUpcomingReleasesMessage       i18n.Key = "no_upcoming_releases"
+	TimezoneUsageMessage            i18n.Key = "timezone_usage"
+	InvalidTimezoneMessage          i18n.Key = "invalid_timezone"
+	SuccessTimezoneMessage          i18n.Key = "success_timezone"
+	DigestTimeUsageMessage          i18n.Key = "digest_time_usage"
+	InvalidDigestTimeMessage        i18n.Key = "invalid_digest_time"
+	SuccessDigestTimeMessage        i18n.Key = "success_digest_time"
+	OutdatedButtonMessage           i18n.Key = "outdated_button"
+	ChooseLanguageMessage           i18n.Key = "choose_language"
+	SuccessLanguageMessage          i18n.Key = "success_language"
+	ChatStartMessage                i18n.Key = "chat_start"
+	ChatSubscriptionsMessage        i18n.Key = "chat_subscriptions"
+	NotChatAdminMessage             i18n.Key = "not_chat_admin"
+	OpenInPrivateMessage            i18n.Key = "open_in_private"
+	TodayReleasesPublicationMessage i18n.Key = "today_releases_publication"
+	MoreReleasesMessage             i18n.Key = "more_releases"
+	StatsUsersMessage               i18n.Key = "stats_users"
+	StatsSubscribersMessage         i18n.Key = "stats_subscribers"
+	StatsReleasesMessage            i18n.Key = "stats_releases"
+	StatsYearReleasesMessage        i18n.Key = "stats_year_releases"
+	StatsLibraryMessage             i18n.Key = "stats_library"
+	StatsUpdaterRunMessage          i18n.Key = "stats_updater_run"
+	StatsNoUpdaterRunMessage        i18n.Key = "stats_no_updater_run"
+	StatsDigestRunMessage           i18n.Key = "stats_digest_run"
+	StatsNoDigestRunMessage         i18n.Key = "stats_no_digest_run"
+	BroadcastComposeMessage         i18n.Key = "broadcast_compose"
+	BroadcastChooseSegmentMessage   i18n.Key = "broadcast_choose_segment"
+	BroadcastConfirmMessage         i18n.Key = "broadcast_confirm"
+	BroadcastNoRecipientsMessage    i18n.Key = "broadcast_no_recipients"
+	BroadcastStartedMessage         i18n.Key = "broadcast_started"
+	BroadcastReportMessage          i18n.Key = "broadcast_report"
+	BroadcastCanceledMessage        i18n.Key = "broadcast_canceled"
+	AddReleaseUsageMessage          i18n.Key = "add_release_usage"
+	EditReleaseUsageMessage         i18n.Key = "edit_release_usage"
+	DeleteReleaseUsageMessage       i18n.Key = "delete_release_usage"
+	MergeArtistsUsageMessage        i18n.Key = "merge_artists_usage"
+	InvalidAdminArgsMessage         i18n.Key = "invalid_admin_args"
+	AdminReleaseNotFoundMessage     i18n.Key = "admin_release_not_found"
+	AdminArtistNotFoundMessage      i18n.Key = "admin_artist_not_found"
+	ReleaseAddedMessage             i18n.Key = "release_added"
+	ReleaseUpdatedMessage           i18n.Key = "release_updated"
+	ReleaseDeletedMessage           i18n.Key = "release_deleted"
+	ArtistsMergedMessage            i18n.Key = "artists_merged"
+	RefreshUsageMessage             i18n.Key = "refresh_usage"
+	ReminderMessage                 i18n.Key = "reminder"
+	ReminderSetMessage              i18n.Key = "reminder_set"
+	ReminderDeletedMessage          i18n.Key = "reminder_deleted"
+	ReleaseAlreadyOutMessage        i18n.Key = "release_already_out"
+	LibraryMessage                  i18n.Key = "library"
+	EmptyLibraryMessage             i18n.Key = "empty_library"
+	ReleaseSavedMessage             i18n.Key = "release_saved"
+	ReleaseUnsavedMessage           i18n.Key = "release_unsaved"
+
+	AlbumTypeText  i18n.Key = "album_type"
+	SingleTypeText i18n.Key = "single_type"
+
+	// BUTTONS
+	TodayButtonText               i18n.Key = "today_button"
+	TodayReleasesButtonText       i18n.Key = "today_releases_button"
+	MonthReleasesButtonText       i18n.Key = "month_releases_button"
+	YearReleasesByMonthButtonText i18n.Key = "year_releases_by_month_button"
+	BackToMonthsButtonText        i18n.Key = "back_to_months_button"
+	BackToYearsButtonText         i18n.Key = "back_to_years_button"
+	UpcomingReleasesButtonText    i18n.Key = "upcoming_releases_button"
+	UpcomingDaysButtonText        i18n.Key = "upcoming_days_button"
+	SubscriptionsButtonText       i18n.Key = "subscriptions_button"
+	LanguageButtonText            i18n.Key = "language_button"
+	RefreshReleasesButtonText     i18n.Key = "refresh_releases_button"
+	TestButtonText                i18n.Key = "test_button"
+	BroadcastButtonText           i18n.Key = "broadcast_button"
+	FollowButtonText              i18n.Key = "follow_button"
+	UnfollowButtonText            i18n.Key = "unfollow_button"
+	BackToListButtonText          i18n.Key = "back_to_list_button"
+	RemindButtonText              i18n.Key = "remind_button"
+	UnremindButtonText            i18n.Key = "unremind_button"
+	SaveButtonText                i18n.Key = "save_button"
+	UnsaveButtonText              i18n.Key = "unsave_button"
+	SavedOrderButtonText          i18n.Key = "saved_order_button"
+	ReleaseDateOrderButtonText    i18n.Key = "release_date_order_button"
+	AllTypesButtonText            i18n.Key = "all_types_button"
+	AlbumsButtonText              i18n.Key = "albums_button"
+	SinglesButtonText             i18n.Key = "singles_button"
+
+	BroadcastAllButtonText         i18n.Key = "broadcast_all_button"
+	BroadcastSubscribersButtonText i18n.Key = "broadcast_subscribers_button"
+	BroadcastConfirmButtonText     i18n.Key = "broadcast_confirm_button"
+	BroadcastCancelButtonText      i18n.Key = "broadcast_cancel_button"
+
+	HistorySubscriptionText       i18n.Key = "history_subscription"
+	TodayReleasesSubscriptionText i18n.Key = "today_releases_subscription"
+	WeeklyDigestSubscriptionText  i18n.Key = "weekly_digest_subscription"
+	ArtistAlertsSubscriptionText  i18n.Key = "artist_alerts_subscription"
+)
 
+const (
 	SingleEmoji = "🎤"
 	AlbumEmoji  = "💿"
 
-	// BUTTONS
-	TodayButtonText               = "Today in Hip Hop History"
-	TodayReleasesButtonText       = "Today releases"
-	MonthReleasesButtonText       = "Month releases"
-	YearReleasesByMonthButtonText = "Year releases by month"
+	ReleaseNumberText = "<b>%d.</b> %s"
+	ReleaseCardText   = "%s %s\n\n<b>%s</b>\n%s\n\n📅 %s, %s"
+	ReleaseIdText     = "\n\n🆔 <code>%d</code>"
 
-	SubscribeButtonText      = "Subscribe"
-	UnsubscribeButtonText    = "Unsubscribe"
-	CheckSubscribeButtonText = "Check subscribe"
+	YoutubeSearchUrl    = "https://www.youtube.com/results?search_query=%s"
+	SpotifySearchUrl    = "https://open.spotify.com/search/%s"
+	AppleMusicSearchUrl = "https://music.apple.com/search?term=%s"
+
+	// BUTTONS
+	SelectedButtonText        = "• %s •"
+	SubscriptionOnButtonText  = "✅ %s"
+	SubscriptionOffButtonText = "❌ %s"
 
-	RefreshReleasesButtonText = "Manual refresh releases"
-	TestButtonText            = "Test message"
+	YoutubeButtonText    = "YouTube"
+	SpotifyButtonText    = "Spotify"
+	AppleMusicButtonText = "Apple Music"
+	ReleasesButtonsInRow = 5
 
 	// COMMANDS
-	StartCommandText = "start"
+	StartCommandText         = "start"
+	FollowCommandText        = "follow"
+	UnfollowCommandText      = "unfollow"
+	FollowingCommandText     = "following"
+	SearchCommandText        = "search"
+	TimezoneCommandText      = "timezone"
+	DigestTimeCommandText    = "digest_time"
+	LanguageCommandText      = "language"
+	DigestCommandText        = "digest"
+	StatsCommandText         = "stats"
+	BroadcastCommandText     = "broadcast"
+	CancelCommandText        = "cancel"
+	AddReleaseCommandText    = "addrelease"
+	EditReleaseCommandText   = "editrelease"
+	DeleteReleaseCommandText = "delrelease"
+	MergeArtistsCommandText  = "mergeartists"
+	RefreshCommandText       = "refresh"
+	MyListCommandText        = "mylist"
 
 	// CALLBACKS
+	CallbackDataMaxLen = 64
+
 	PrevReleasesButtonText = "⬅️"
 	NextReleasesButtonText = "➡️"
 
-	PreviousReleasesCallbackText      = "prev_releases"
-	NextReleasesCallbackText          = "next_releases"
-	PreviousTodayReleasesCallbackText = "prev_today_releases"
-	NextTodayReleasesCallbackText     = "next_today_releases"
-	PageCountCallbackText             = "pageCount"
+	PageCountCallbackText = "pageCount"
+
+	FollowArtistCallbackPrefix     = "follow:"
+	UnfollowArtistCallbackPrefix   = "unfollow:"
+	CalendarCallbackPrefix         = "cal:"
+	SubscriptionCallbackPrefix     = "sub:"
+	ReleaseCallbackPrefix          = "rel:"
+	ListCallbackPrefix             = "ls"
+	LanguageCallbackPrefix         = "lang:"
+	ChatSubscriptionCallbackPrefix = "chatsub:"
+	BroadcastCallbackPrefix        = "bc:"
+	RemindCallbackPrefix           = "remind:"
+	UnremindCallbackPrefix         = "unremind:"
+	SaveCallbackPrefix             = "save:"
+	UnsaveCallbackPrefix           = "unsave:"
+
+	StatsTimeLayout    = "02.01.2006 15:04"
+	ReleaseDateLayout  = "2006-01-02"
+	RefreshMonthLayout = "2006-01"
+
+	// поля релиза в /editrelease и типы релиза в /addrelease
+	ReleaseTitleField  = "title"
+	ReleaseArtistField = "artist"
+	ReleaseTypeField   = "type"
+	ReleaseDateField   = "date"
+	ReleaseCoverField  = "cover"
+	AlbumTypeArg       = "album"
+	SingleTypeArg      = "single"
 )
 
+var catalog = i18n.NewCatalog(i18n.DefaultLang, map[i18n.Lang]i18n.Bundle{
+	i18n.Ru: ruMessages,
+	i18n.En: enMessages,
+})
+
+// T возвращает текст сообщения на языке lang.
+func T(lang i18n.Lang, key i18n.Key, args ...any) string {
+	return catalog.T(lang, key, args...)
+}
+
+// ReplyKeyboardButtons - кнопки reply-клавиатуры, Telegram присылает их подпись
+// на языке, который был у пользователя в момент отправки клавиатуры.
+var ReplyKeyboardButtons = []i18n.Key{
+	TodayButtonText,
+	TodayReleasesButtonText,
+	MonthReleasesButtonText,
+	YearReleasesByMonthButtonText,
+	UpcomingReleasesButtonText,
+	SubscriptionsButtonText,
+	LanguageButtonText,
+	RefreshReleasesButtonText,
+	TestButtonText,
+	BroadcastButtonText,
+}
+
+// LanguageNames пишутся на самом языке, чтобы их можно было найти в любом интерфейсе.
+var LanguageNames = map[i18n.Lang]string{
+	i18n.Ru: "🇷🇺 Русский",
+	i18n.En: "🇬🇧 English",
+}
+
+// UpcomingPeriods - варианты периода, на который показываются предстоящие релизы.
+var UpcomingPeriods = []int{7, 14, 30}
+
+var SubscriptionKindsText = map[models.SubscriptionKind]i18n.Key{
+	models.HistorySubscription:       HistorySubscriptionText,
+	models.TodayReleasesSubscription: TodayReleasesSubscriptionText,
+	models.WeeklyDigestSubscription:  WeeklyDigestSubscriptionText,
+	models.ArtistAlertsSubscription:  ArtistAlertsSubscriptionText,
+}
+
+// ReleaseTypeFilters - порядок кнопок фильтра по типу релиза.
+var ReleaseTypeFilters = []models.ReleaseType{models.AnyType, models.Album, models.Single}
+
+var ReleaseTypeFiltersText = map[models.ReleaseType]i18n.Key{
+	models.AnyType: AllTypesButtonText,
+	models.Album:   AlbumsButtonText,
+	models.Single:  SinglesButtonText,
+}
+
 var NumbersToEmojiMapping = map[int]string{
 	0: "0️⃣",
 	1: "1️⃣",
@@ -0,0 +1,123 @@
+package bot
+
+import "hip-hop-geek/internal/i18n"
+
+var enMessages = i18n.Bundle{
+	ErrorAdminMessage:               "An error occurred: %s",
+	UpdateErrorAdminMessage:         "Error while handling a message from user %d: %s",
+	UpdaterErrorAdminMessage:        "Releases refresh finished with errors, the run is not recorded in stats:\n%s",
+	ErrorUserMessage:                "Something went wrong on the server while handling your message.",
+	ErrorPostsNotFound:              "Nothing happened in hip hop on this day",
+	TodayInHistoryMessage:           "Today in Hip Hop History:\n%s",
+	SubscriptionsMessage:            "Choose your digests. Daily digests arrive at %s (%s), the weekly one on Mondays at the same time",
+	SubscribedMessage:               "\"%s\" is on",
+	UnsubscribedMessage:             "\"%s\" is off",
+	ReleasesNotFoundMessage:         "No releases found",
+	NoTodayReleasesMessage:          "No releases today :(",
+	StartCommandMessageText:         "Hi! I'm Hip Hop Geek, a bot that knows every release and event in hip hop. Here is a keyboard with everything I can do",
+	RefreshReleasesStartMessageText: "Starting releases refresh for %s",
+	RefreshReleasesEndMessageText:   "Releases refresh finished",
+	FollowUsageMessage:              "Name an artist: /%s <artist name>",
+	ArtistNotFoundMessage:           "Artist %s not found",
+	SuccessFollowMessage:            "You will get new releases of %s",
+	AlreadyFollowingMessage:         "You already follow %s",
+	SuccessUnfollowMessage:          "You unfollowed %s",
+	FollowedArtistsMessage:          "Artists you follow:",
+	NoFollowedArtistsMessage:        "You don't follow anyone yet. Use /follow <artist name>",
+	NewFollowedReleaseMessage:       "New release by an artist you follow:",
+	SearchUsageMessage:              "Tell me what to look for: /search <artist or title>",
+	ChooseYearMessage:               "Choose a year",
+	ChooseMonthMessage:              "Releases of %d, choose a month",
+	UpcomingReleasesMessage:         "Releases in the next %d days",
+	NoUpcomingReleasesMessage:       "No releases in the next %d days",
+	TimezoneUsageMessage:            "Set your timezone, for example: /timezone Europe/London. Current: %s",
+	InvalidTimezoneMessage:          "Unknown timezone %s. Use IANA names like Europe/Berlin or America/New_York",
+	SuccessTimezoneMessage:          "Timezone changed to %s, the daily digest will arrive at %s your time",
+	DigestTimeUsageMessage:          "Set the digest time as HH:MM, for example: /digest_time 09:30. Current: %s",
+	InvalidDigestTimeMessage:        "Can't read time %s, use HH:MM",
+	SuccessDigestTimeMessage:        "The daily digest will arrive at %s (%s)",
+	OutdatedButtonMessage:           "This button is outdated, open the list again",
+	ChooseLanguageMessage:           "Choose a language",
+	SuccessLanguageMessage:          "I speak English now",
+	ChatStartMessage:                "Hi! I can post a daily hip hop digest here. Chat admins can choose digests with /digest, set the timezone with /timezone and the time with /digest_time",
+	ChatSubscriptionsMessage:        "Choose digests for this chat. Daily digests arrive at %s (%s), the weekly one on Mondays at the same time",
+	NotChatAdminMessage:             "Only chat admins can change chat digests",
+	OpenInPrivateMessage:            "This button works in a private chat with the bot: @%s",
+	TodayReleasesPublicationMessage: "Releases of %s",
+	MoreReleasesMessage:             "...and %d more in @%s",
+	StatsUsersMessage:               "👥 Users: %d (blocked the bot: %d), groups and channels: %d",
+	StatsSubscribersMessage:         "<b>Subscribers</b>",
+	StatsReleasesMessage:            "<b>Releases</b>",
+	StatsYearReleasesMessage:        "%d: %d albums, %d singles",
+	StatsLibraryMessage:             "Without cover: %d, artists: %d",
+	StatsUpdaterRunMessage:          "🔄 Last update: %s, took %s, new releases: %d",
+	StatsNoUpdaterRunMessage:        "🔄 Releases have not been updated yet",
+	StatsDigestRunMessage:           "📬 Last digest: %s, recipients: %d, failed: %d",
+	StatsNoDigestRunMessage:         "📬 No digest has been sent yet",
+	BroadcastComposeMessage:         "Send the message to broadcast: text, photo or a forwarded post. Cancel - /cancel",
+	BroadcastChooseSegmentMessage:   "Above is how users will see the message. Who should get it?",
+	BroadcastConfirmMessage:         "Send the message to %d recipients (%s)?",
+	BroadcastNoRecipientsMessage:    "Nobody is in this segment, choose another one",
+	BroadcastStartedMessage:         "Broadcast started, I will send a report when it finishes",
+	BroadcastReportMessage:          "Broadcast finished. Delivered: %d, blocked the bot: %d, failed: %d",
+	BroadcastCanceledMessage:        "Broadcast canceled",
+	AddReleaseUsageMessage:          "Usage: /addrelease Artist - Title; 2026-10-18; album or single; cover url (optional)",
+	EditReleaseUsageMessage:         "Usage: /editrelease id field value\nFields: title, artist, type (album or single), date (2026-10-18), cover (cover url)\nThe release id is shown on its card",
+	DeleteReleaseUsageMessage:       "Usage: /delrelease id\nThe release id is shown on its card",
+	MergeArtistsUsageMessage:        "Usage: /mergeartists Duplicate; Artist\nReleases and followers of the duplicate move to the artist, and the duplicate is deleted",
+	InvalidAdminArgsMessage:         "Invalid arguments: %s\n\n%s",
+	AdminReleaseNotFoundMessage:     "Release with id %d not found",
+	AdminArtistNotFoundMessage:      "Artist %s not found",
+	ReleaseAddedMessage:             "Release added:\n\n%s",
+	ReleaseUpdatedMessage:           "Release updated:\n\n%s",
+	ReleaseDeletedMessage:           "Release %d deleted, updates will not bring it back",
+	ArtistsMergedMessage:            "Artist %s merged into %s",
+	RefreshUsageMessage:             "Usage: /refresh - refresh releases for %s, /refresh 2025 - for a year, /refresh 2025-03 - for a month",
+	ReminderMessage:                 "🔔 The release you asked to be reminded about is out:",
+	ReminderSetMessage:              "I will remind you on the release day",
+	ReminderDeletedMessage:          "Reminder removed",
+	ReleaseAlreadyOutMessage:        "This release is already out",
+	LibraryMessage:                  "My list",
+	EmptyLibraryMessage:             "Your list is empty. Save releases with the ❤️ button on a release card",
+	ReleaseSavedMessage:             "Release saved to /mylist",
+	ReleaseUnsavedMessage:           "Release removed from your list",
+
+	AlbumTypeText:  "Album",
+	SingleTypeText: "Single",
+
+	TodayButtonText:               "Today in Hip Hop History",
+	TodayReleasesButtonText:       "Today releases",
+	MonthReleasesButtonText:       "Month releases",
+	YearReleasesByMonthButtonText: "Year releases by month",
+	BackToMonthsButtonText:        "⬅️ Months",
+	BackToYearsButtonText:         "⏫ Years",
+	UpcomingReleasesButtonText:    "Upcoming releases",
+	UpcomingDaysButtonText:        "%d days",
+	SubscriptionsButtonText:       "Subscriptions",
+	LanguageButtonText:            "🌐 Language",
+	RefreshReleasesButtonText:     "Manual refresh releases",
+	TestButtonText:                "Test message",
+	BroadcastButtonText:           "Broadcast to users",
+	FollowButtonText:              "Follow %s",
+	UnfollowButtonText:            "Unfollow %s",
+	BackToListButtonText:          "⬅️ Back to list",
+	RemindButtonText:              "🔔 Remind me",
+	UnremindButtonText:            "🔕 Cancel reminder",
+	SaveButtonText:                "❤️ Save",
+	UnsaveButtonText:              "💔 Remove from list",
+	SavedOrderButtonText:          "Recently saved",
+	ReleaseDateOrderButtonText:    "By release date",
+	AllTypesButtonText:            "All",
+	AlbumsButtonText:              "Albums",
+	SinglesButtonText:             "Singles",
+
+	BroadcastAllButtonText:         "Everyone",
+	BroadcastSubscribersButtonText: "Subscribers",
+	BroadcastConfirmButtonText:     "✅ Send",
+	BroadcastCancelButtonText:      "Cancel",
+
+	HistorySubscriptionText:       "Today in Hip Hop History",
+	TodayReleasesSubscriptionText: "Today releases",
+	WeeklyDigestSubscriptionText:  "Weekly upcoming releases",
+	ArtistAlertsSubscriptionText:  "Followed artists releases",
+}
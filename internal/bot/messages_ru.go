@@ -0,0 +1,123 @@
+package bot
+
+import "hip-hop-geek/internal/i18n"
+
+var ruMessages = i18n.Bundle{
+	ErrorAdminMessage:               "Произошла ошибка: %s",
+	UpdateErrorAdminMessage:         "Ошибка при обработке сообщения пользователя %d: %s",
+	UpdaterErrorAdminMessage:        "Обновление релизов завершилось с ошибками, запуск не записан в статистику:\n%s",
+	ErrorUserMessage:                "Во время обработки сообщения произошла ошибка на сервере.",
+	ErrorPostsNotFound:              "Сегодня в хип хопе не происходило никаких событий",
+	TodayInHistoryMessage:           "Сегодня в истории хип хопа:\n%s",
+	SubscriptionsMessage:            "Выберите рассылки. Ежедневные рассылки приходят в %s (%s), еженедельная - по понедельникам в это же время",
+	SubscribedMessage:               "Подписка на \"%s\" включена",
+	UnsubscribedMessage:             "Подписка на \"%s\" выключена",
+	ReleasesNotFoundMessage:         "Релизы не найдены",
+	NoTodayReleasesMessage:          "Сегодня релизов нет :(",
+	StartCommandMessageText:         "Привет! Я бот - Хип Хоп гик, который знает обо всех релизах и событиях в жизни хип хопа. Отправляю тебе клавиатуру с нужными командами",
+	RefreshReleasesStartMessageText: "Запускаю обновление релизов за %s",
+	RefreshReleasesEndMessageText:   "Обновление релизов завершено",
+	FollowUsageMessage:              "Укажите исполнителя: /%s <имя исполнителя>",
+	ArtistNotFoundMessage:           "Исполнитель %s не найден",
+	SuccessFollowMessage:            "Вы подписались на новые релизы %s",
+	AlreadyFollowingMessage:         "Вы уже следите за %s",
+	SuccessUnfollowMessage:          "Вы отписались от релизов %s",
+	FollowedArtistsMessage:          "Исполнители, за которыми вы следите:",
+	NoFollowedArtistsMessage:        "Вы пока ни за кем не следите. Используйте /follow <имя исполнителя>",
+	NewFollowedReleaseMessage:       "Новый релиз исполнителя, за которым вы следите:",
+	SearchUsageMessage:              "Напишите, что искать: /search <исполнитель или название>",
+	ChooseYearMessage:               "Выберите год",
+	ChooseMonthMessage:              "Релизы за %d год, выберите месяц",
+	UpcomingReleasesMessage:         "Релизы на ближайшие %d дней",
+	NoUpcomingReleasesMessage:       "В ближайшие %d дней релизов не найдено",
+	TimezoneUsageMessage:            "Укажите часовой пояс, например: /timezone Europe/Moscow. Сейчас: %s",
+	InvalidTimezoneMessage:          "Не знаю часовой пояс %s. Используйте названия из базы IANA, например Europe/Berlin или Asia/Tomsk",
+	SuccessTimezoneMessage:          "Часовой пояс изменен на %s, ежедневная рассылка придет в %s по вашему времени",
+	DigestTimeUsageMessage:          "Укажите время рассылки в формате ЧЧ:ММ, например: /digest_time 09:30. Сейчас: %s",
+	InvalidDigestTimeMessage:        "Не понимаю время %s, нужен формат ЧЧ:ММ",
+	SuccessDigestTimeMessage:        "Ежедневная рассылка будет приходить в %s (%s)",
+	OutdatedButtonMessage:           "Эта кнопка устарела, откройте список заново",
+	ChooseLanguageMessage:           "Выберите язык",
+	SuccessLanguageMessage:          "Теперь я говорю по-русски",
+	ChatStartMessage:                "Привет! Я могу присылать сюда ежедневную рассылку о хип хопе. Администраторы чата могут выбрать рассылки командой /digest, часовой пояс - /timezone, время - /digest_time",
+	ChatSubscriptionsMessage:        "Выберите рассылки для этого чата. Ежедневные рассылки приходят в %s (%s), еженедельная - по понедельникам в это же время",
+	NotChatAdminMessage:             "Настраивать рассылки чата могут только его администраторы",
+	OpenInPrivateMessage:            "Эта кнопка работает в личном чате с ботом: @%s",
+	TodayReleasesPublicationMessage: "Релизы %s",
+	MoreReleasesMessage:             "...и еще %d - в @%s",
+	StatsUsersMessage:               "👥 Пользователей: %d (заблокировали бота: %d), групп и каналов: %d",
+	StatsSubscribersMessage:         "<b>Подписчики</b>",
+	StatsReleasesMessage:            "<b>Релизы</b>",
+	StatsYearReleasesMessage:        "%d: %d альбомов, %d синглов",
+	StatsLibraryMessage:             "Без обложки: %d, исполнителей: %d",
+	StatsUpdaterRunMessage:          "🔄 Последнее обновление: %s, заняло %s, новых релизов: %d",
+	StatsNoUpdaterRunMessage:        "🔄 Обновление релизов еще не запускалось",
+	StatsDigestRunMessage:           "📬 Последняя рассылка: %s, получателей: %d, не доставлено: %d",
+	StatsNoDigestRunMessage:         "📬 Рассылка еще не отправлялась",
+	BroadcastComposeMessage:         "Отправьте сообщение для рассылки: текст, фото или пересланный пост. Отменить - /cancel",
+	BroadcastChooseSegmentMessage:   "Выше - так сообщение увидят пользователи. Кому его отправить?",
+	BroadcastConfirmMessage:         "Отправить сообщение %d получателям (%s)?",
+	BroadcastNoRecipientsMessage:    "В этом сегменте нет получателей, выберите другой",
+	BroadcastStartedMessage:         "Рассылка началась, пришлю отчет, когда она закончится",
+	BroadcastReportMessage:          "Рассылка завершена. Доставлено: %d, заблокировали бота: %d, ошибок: %d",
+	BroadcastCanceledMessage:        "Рассылка отменена",
+	AddReleaseUsageMessage:          "Формат: /addrelease Исполнитель - Название; 2026-10-18; album или single; ссылка на обложку (необязательно)",
+	EditReleaseUsageMessage:         "Формат: /editrelease id поле значение\nПоля: title, artist, type (album или single), date (2026-10-18), cover (ссылка на обложку)\nid релиза показан в его карточке",
+	DeleteReleaseUsageMessage:       "Формат: /delrelease id\nid релиза показан в его карточке",
+	MergeArtistsUsageMessage:        "Формат: /mergeartists Дубликат; Исполнитель\nРелизы и подписчики дубликата перейдут к исполнителю, а дубликат будет удален",
+	InvalidAdminArgsMessage:         "Неверные параметры: %s\n\n%s",
+	AdminReleaseNotFoundMessage:     "Релиз с id %d не найден",
+	AdminArtistNotFoundMessage:      "Исполнитель %s не найден",
+	ReleaseAddedMessage:             "Релиз добавлен:\n\n%s",
+	ReleaseUpdatedMessage:           "Релиз обновлен:\n\n%s",
+	ReleaseDeletedMessage:           "Релиз %d удален, при обновлении он не вернется",
+	ArtistsMergedMessage:            "Исполнитель %s объединен с %s",
+	RefreshUsageMessage:             "Формат: /refresh - обновить релизы за %s, /refresh 2025 - за год, /refresh 2025-03 - за месяц",
+	ReminderMessage:                 "🔔 Вышел релиз, о котором вы просили напомнить:",
+	ReminderSetMessage:              "Напомню в день выхода релиза",
+	ReminderDeletedMessage:          "Напоминание удалено",
+	ReleaseAlreadyOutMessage:        "Релиз уже вышел",
+	LibraryMessage:                  "Мой список",
+	EmptyLibraryMessage:             "В вашем списке пока нет релизов. Сохраняйте их кнопкой ❤️ в карточке релиза",
+	ReleaseSavedMessage:             "Релиз сохранен в /mylist",
+	ReleaseUnsavedMessage:           "Релиз удален из вашего списка",
+
+	AlbumTypeText:  "Альбом",
+	SingleTypeText: "Сингл",
+
+	TodayButtonText:               "Сегодня в истории хип хопа",
+	TodayReleasesButtonText:       "Релизы сегодня",
+	MonthReleasesButtonText:       "Релизы месяца",
+	YearReleasesByMonthButtonText: "Релизы года по месяцам",
+	BackToMonthsButtonText:        "⬅️ Месяцы",
+	BackToYearsButtonText:         "⏫ Годы",
+	UpcomingReleasesButtonText:    "Ближайшие релизы",
+	UpcomingDaysButtonText:        "%d дней",
+	SubscriptionsButtonText:       "Рассылки",
+	LanguageButtonText:            "🌐 Язык",
+	RefreshReleasesButtonText:     "Обновить релизы вручную",
+	TestButtonText:                "Тестовое сообщение",
+	BroadcastButtonText:           "Рассылка пользователям",
+	FollowButtonText:              "Следить за %s",
+	UnfollowButtonText:            "Не следить за %s",
+	BackToListButtonText:          "⬅️ К списку",
+	RemindButtonText:              "🔔 Напомнить о выходе",
+	UnremindButtonText:            "🔕 Не напоминать",
+	SaveButtonText:                "❤️ В мой список",
+	UnsaveButtonText:              "💔 Убрать из списка",
+	SavedOrderButtonText:          "Недавно сохраненные",
+	ReleaseDateOrderButtonText:    "По дате выхода",
+	AllTypesButtonText:            "Все",
+	AlbumsButtonText:              "Альбомы",
+	SinglesButtonText:             "Синглы",
+
+	BroadcastAllButtonText:         "Всем",
+	BroadcastSubscribersButtonText: "Подписчикам",
+	BroadcastConfirmButtonText:     "✅ Отправить",
+	BroadcastCancelButtonText:      "Отмена",
+
+	HistorySubscriptionText:       "Сегодня в истории хип хопа",
+	TodayReleasesSubscriptionText: "Релизы дня",
+	WeeklyDigestSubscriptionText:  "Релизы на неделю вперед",
+	ArtistAlertsSubscriptionText:  "Релизы отслеживаемых исполнителей",
+}
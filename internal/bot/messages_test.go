@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestMessagesCatalog(t *testing.T) {
+	t.Run("every message is translated", func(t *testing.T) {
+		for _, lang := range i18n.Supported {
+			assert.Empty(t, catalog.Missing(lang), "missing %s translations", lang)
+		}
+	})
+
+	t.Run("reply buttons are matched in any language", func(t *testing.T) {
+		for _, lang := range i18n.Supported {
+			for _, button := range ReplyKeyboardButtons {
+				key, ok := catalog.Match(T(lang, button), ReplyKeyboardButtons)
+				assert.True(t, ok)
+				assert.Equal(t, button, key)
+			}
+		}
+	})
+
+	t.Run("release caption uses language month names", func(t *testing.T) {
+		release := models.Release{
+			Artist:  models.Artist{Name: "Kendrick Lamar"},
+			Title:   "GNX",
+			Type:    models.Album,
+			OutDate: types.CustomDate{Time: time.Date(2024, time.November, 22, 0, 0, 0, 0, time.UTC)},
+		}
+		assert.Equal(
+			t,
+			"💿 <b>Kendrick Lamar - GNX</b> (<i>22 ноября 2024</i>)",
+			GenerateCaption(i18n.Ru, release),
+		)
+		assert.Equal(
+			t,
+			"💿 <b>Kendrick Lamar - GNX</b> (<i>22 November 2024</i>)",
+			GenerateCaption(i18n.En, release),
+		)
+	})
+}
@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/fetcher"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// PublishChannels читает из PUBLISH_CHANNEL_IDS каналы, в которые бот сам публикует посты.
+func PublishChannels() []int64 {
+	var channels []int64
+	for _, value := range strings.Split(os.Getenv("PUBLISH_CHANNEL_IDS"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		channelId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Printf("invalid channel id %s in PUBLISH_CHANNEL_IDS: %s", value, err)
+			continue
+		}
+		channels = append(channels, channelId)
+	}
+
+	return channels
+}
+
+// PublishLang - язык постов в каналах из PUBLISH_LANGUAGE, по умолчанию русский.
+func PublishLang() i18n.Lang {
+	lang, ok := i18n.Parse(os.Getenv("PUBLISH_LANGUAGE"))
+	if !ok {
+		return i18n.DefaultLang
+	}
+
+	return lang
+}
+
+// PublishDue публикует в каналы событие из истории и релизы дня, а по понедельникам -
+// релизы на неделю. Посты выходят во время рассылки по умолчанию по часовому поясу сервера.
+// Каждая публикация записывается в базу, поэтому после перезапуска посты не дублируются.
+func (b *TGBot) PublishDue(now time.Time) {
+	channels := PublishChannels()
+	if len(channels) == 0 {
+		return
+	}
+
+	// что уже опубликовано сегодня, проверяется по таблице publications
+	if !isDigestDue(time.Local, DefaultDigestTime(), "", now) {
+		return
+	}
+
+	localNow := now.In(time.Local)
+	date := localNow.Format(DigestDateLayout)
+	day, _ := time.Parse(DigestDateLayout, date)
+	lang := PublishLang()
+	for _, kind := range DigestKinds {
+		if kind == models.WeeklyDigestSubscription && localNow.Weekday() != WeeklyDigestWeekday {
+			continue
+		}
+
+		pending := make([]int64, 0, len(channels))
+		for _, channelId := range channels {
+			published, err := b.Service.IsPublished(channelId, kind, date)
+			if err != nil {
+				log.Printf("error while checking %s publication in channel %d: %s", kind, channelId, err)
+				continue
+			}
+			if !published {
+				pending = append(pending, channelId)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		posts, err := b.GeneratePublication(lang, kind, day)
+		if err != nil {
+			log.Printf("error while preparing %s publication for %s: %s", kind, date, err)
+			continue
+		}
+
+		for _, channelId := range pending {
+			b.publish(channelId, kind, date, posts)
+		}
+	}
+}
+
+// GeneratePublication собирает посты для канала. Пустой список - публиковать нечего,
+// например в этот день не было релизов.
+func (b *TGBot) GeneratePublication(
+	lang i18n.Lang,
+	kind models.SubscriptionKind,
+	day time.Time,
+) ([]tgbotapi.PhotoConfig, error) {
+	switch kind {
+	case models.HistorySubscription:
+		events, err := b.Service.GetEventsByDate(day)
+		if err != nil {
+			if errors.Is(err, fetcher.ErrPostsNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		posts := make([]tgbotapi.PhotoConfig, 0, len(events))
+		for _, event := range events {
+			post := tgbotapi.NewPhoto(0, tgbotapi.FileURL(event.Url))
+			post.Caption = T(lang, TodayInHistoryMessage, event.Text)
+			posts = append(posts, post)
+		}
+		return posts, nil
+
+	case models.TodayReleasesSubscription:
+		releases, err := b.Service.GetReleasesByDay(
+			day.Year(), day.Month(), day.Day(),
+			models.AnyType,
+			NoLimit, NoOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, nil
+		}
+
+		return []tgbotapi.PhotoConfig{b.TodayReleasesPost(0, lang, day, releases)}, nil
+
+	case models.WeeklyDigestSubscription:
+		releases, err := b.Service.GetReleasesByPeriod(
+			day,
+			day.AddDate(0, 0, WeeklyDigestDays-1),
+			StandardReleasesLimit,
+			NoOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, nil
+		}
+
+		return []tgbotapi.PhotoConfig{b.WeeklyReleasesPost(0, lang, releases)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown publication kind %s", kind)
+}
+
+// TodayReleasesPost - пост с релизами дня без клавиатуры для каналов и групп,
+// где кнопки списков не работают.
+func (b *TGBot) TodayReleasesPost(
+	chatId int64,
+	lang i18n.Lang,
+	day time.Time,
+	releases []models.Release,
+) tgbotapi.PhotoConfig {
+	shown := releases[:min(len(releases), StandardReleasesLimit)]
+	post := tgbotapi.NewPhoto(chatId, b.ReleasesPhoto(shown))
+	post.Caption = GenerateTodayPublicationCaption(lang, day, releases, b.Self.UserName)
+	post.ParseMode = tgbotapi.ModeHTML
+	return post
+}
+
+// WeeklyReleasesPost - пост с релизами на неделю без клавиатуры.
+func (b *TGBot) WeeklyReleasesPost(
+	chatId int64,
+	lang i18n.Lang,
+	releases []models.Release,
+) tgbotapi.PhotoConfig {
+	post := tgbotapi.NewPhoto(chatId, b.ReleasesPhoto(releases))
+	post.Caption = GenerateUpcomingCaption(lang, WeeklyDigestDays, releases)
+	post.ParseMode = tgbotapi.ModeHTML
+	return post
+}
+
+// GenerateTodayPublicationCaption - подпись поста с релизами дня. Подпись к фото
+// ограничена 1024 символами, поэтому в пост попадают только первые релизы,
+// а за остальными отправляем в бота.
+func GenerateTodayPublicationCaption(
+	lang i18n.Lang,
+	day time.Time,
+	releases []models.Release,
+	botUsername string,
+) string {
+	caption := make([]string, 0, StandardReleasesLimit+3)
+	caption = append(
+		caption,
+		"<b>"+T(lang, TodayReleasesPublicationMessage, i18n.FormatDate(lang, day))+"</b>",
+		"",
+	)
+
+	shown := releases[:min(len(releases), StandardReleasesLimit)]
+	for i, release := range shown {
+		caption = append(
+			caption,
+			fmt.Sprintf(ReleaseNumberText, i+1, GenerateCaptionForTodayRelease(release)),
+		)
+	}
+	if len(releases) > len(shown) {
+		caption = append(caption, "", T(lang, MoreReleasesMessage, len(releases)-len(shown), botUsername))
+	}
+
+	return strings.Join(caption, "\n")
+}
+
+// MediaGroupMaxSize - больше фото в один пост Telegram не собирает.
+const MediaGroupMaxSize = 10
+
+// publish отправляет посты в канал и запоминает публикацию.
+// Если публиковать нечего, тоже запоминаем, чтобы не проверять день повторно.
+// Несколько постов уходят одним альбомом: он либо отправлен целиком, либо нет,
+// поэтому после частичной ошибки повторная публикация не дублирует уже вышедшие фото.
+func (b *TGBot) publish(
+	channelId int64,
+	kind models.SubscriptionKind,
+	date string,
+	posts []tgbotapi.PhotoConfig,
+) {
+	publication := models.Publication{ChannelId: channelId, Kind: kind, Date: date}
+	if len(posts) > 0 {
+		messageId, err := b.sendPosts(channelId, posts)
+		if err != nil {
+			log.Printf("error while publishing %s to channel %d: %s", kind, channelId, err)
+			return
+		}
+		publication.MessageId = messageId
+	}
+
+	if err := b.Service.AddPublication(publication); err != nil {
+		log.Printf("error while saving %s publication in channel %d: %s", kind, channelId, err)
+	}
+}
+
+// sendPosts отправляет посты одним сообщением и возвращает id первого из них.
+func (b *TGBot) sendPosts(chatId int64, posts []tgbotapi.PhotoConfig) (int, error) {
+	if len(posts) == 1 {
+		post := posts[0]
+		post.ChatID = chatId
+		msg, err := b.Send(post)
+		return msg.MessageID, err
+	}
+
+	if len(posts) > MediaGroupMaxSize {
+		log.Printf("%d posts do not fit in one album, only first %d are sent", len(posts), MediaGroupMaxSize)
+		posts = posts[:MediaGroupMaxSize]
+	}
+	media := make([]interface{}, 0, len(posts))
+	for _, post := range posts {
+		photo := tgbotapi.NewInputMediaPhoto(post.File)
+		photo.Caption = post.Caption
+		photo.ParseMode = post.ParseMode
+		media = append(media, photo)
+	}
+
+	messages, err := b.SendMediaGroup(tgbotapi.NewMediaGroup(chatId, media))
+	if err != nil {
+		return 0, err
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+	return messages[0].MessageID, nil
+}
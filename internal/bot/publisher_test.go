@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+type stubPublicationsService struct {
+	HipHopService
+	publications []models.Publication
+}
+
+func (s *stubPublicationsService) AddPublication(publication models.Publication) error {
+	s.publications = append(s.publications, publication)
+	return nil
+}
+
+func TestPublication(t *testing.T) {
+	t.Run("channels from env", func(t *testing.T) {
+		t.Setenv("PUBLISH_CHANNEL_IDS", "-1001, -1002,,oops")
+		assert.Equal(t, []int64{-1001, -1002}, PublishChannels())
+
+		t.Setenv("PUBLISH_CHANNEL_IDS", "")
+		assert.Empty(t, PublishChannels())
+	})
+
+	t.Run("today caption is cut to page size", func(t *testing.T) {
+		day := time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)
+		releases := make([]models.Release, StandardReleasesLimit+3)
+		for i := range releases {
+			releases[i] = models.Release{
+				Title:  fmt.Sprintf("Release %d", i+1),
+				Artist: models.Artist{Name: "Artist"},
+			}
+		}
+
+		caption := GenerateTodayPublicationCaption(i18n.Ru, day, releases, "hiphopgeek_bot")
+		assert.True(t, strings.HasPrefix(caption, "<b>Релизы 12 августа 2024</b>"))
+		assert.Contains(t, caption, "Release 10")
+		assert.NotContains(t, caption, "Release 11")
+		assert.True(t, strings.HasSuffix(caption, "...и еще 3 - в @hiphopgeek_bot"))
+
+		caption = GenerateTodayPublicationCaption(i18n.En, day, releases[:2], "hiphopgeek_bot")
+		assert.NotContains(t, caption, "more")
+	})
+	t.Run("history is published as one album", func(t *testing.T) {
+		posts := make([]tgbotapi.PhotoConfig, 3)
+		for i := range posts {
+			posts[i] = tgbotapi.NewPhoto(0, tgbotapi.FileURL(fmt.Sprintf("https://example.com/%d.jpg", i)))
+			posts[i].Caption = fmt.Sprintf("event %d", i)
+		}
+
+		api := &stubRequester{result: []byte(`[{"message_id": 7}, {"message_id": 8}, {"message_id": 9}]`)}
+		service := &stubPublicationsService{}
+		b := &TGBot{BotAPI: &tgbotapi.BotAPI{}, Service: service}
+		b.sender, _ = newTestSender(api)
+
+		b.publish(-1001, models.HistorySubscription, "2024-08-12", posts)
+		if assert.Len(t, api.sent, 1) {
+			album := api.sent[0].(tgbotapi.MediaGroupConfig)
+			assert.Equal(t, int64(-1001), album.ChatID)
+			assert.Len(t, album.Media, 3)
+			assert.Equal(t, "event 2", album.Media[2].(tgbotapi.InputMediaPhoto).Caption)
+		}
+		assert.Equal(t, []models.Publication{{
+			ChannelId: -1001,
+			Kind:      models.HistorySubscription,
+			Date:      "2024-08-12",
+			MessageId: 7,
+		}}, service.publications)
+
+		// альбом не отправлен - публикация не записана и повторится целиком
+		api = &stubRequester{errs: []error{&tgbotapi.Error{Code: http.StatusBadRequest, Message: "Bad Request"}}}
+		service = &stubPublicationsService{}
+		b = &TGBot{BotAPI: &tgbotapi.BotAPI{}, Service: service}
+		b.sender, _ = newTestSender(api)
+
+		b.publish(-1001, models.HistorySubscription, "2024-08-12", posts)
+		assert.Len(t, api.sent, 1)
+		assert.Empty(t, service.publications)
+	})
+}
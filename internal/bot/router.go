@@ -0,0 +1,289 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// HandlerFunc обрабатывает обновление из личного чата. Пользователя заполняет LoadUser,
+// до него в цепочке middleware user равен nil. Ошибку показывает пользователю ReportErrors.
+type HandlerFunc func(upd tgbotapi.Update, user *models.User) error
+
+// Middleware оборачивает обработчик: загружает пользователя, проверяет права, логирует.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Router выбирает обработчик по команде, кнопке reply-клавиатуры или префиксу
+// callback data. Общие middleware из Use оборачивают любой маршрут, в том числе Unknown,
+// middleware маршрута - только его обработчик.
+type Router struct {
+	middlewares []Middleware
+	commands    map[string]HandlerFunc
+	buttons     map[i18n.Key]HandlerFunc
+	buttonKeys  []i18n.Key
+	callbacks   map[string]HandlerFunc
+	states      []stateRoute
+	unknown     HandlerFunc
+}
+
+// stateRoute - обработчик сообщений пользователя, от которого бот ждет ответа,
+// например черновика рассылки.
+type stateRoute struct {
+	active  func(userId int64) bool
+	handler HandlerFunc
+}
+
+func NewRouter() *Router {
+	return &Router{
+		commands:  make(map[string]HandlerFunc),
+		buttons:   make(map[i18n.Key]HandlerFunc),
+		callbacks: make(map[string]HandlerFunc),
+		unknown:   func(tgbotapi.Update, *models.User) error { return nil },
+	}
+}
+
+// Use добавляет общие middleware, первый из них выполняется первым.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r *Router) Command(command string, handler HandlerFunc, middlewares ...Middleware) {
+	r.commands[command] = chain(handler, middlewares)
+}
+
+// Button регистрирует кнопку reply-клавиатуры, ее подпись ищется на всех языках каталога.
+func (r *Router) Button(key i18n.Key, handler HandlerFunc, middlewares ...Middleware) {
+	if _, ok := r.buttons[key]; !ok {
+		r.buttonKeys = append(r.buttonKeys, key)
+	}
+	r.buttons[key] = chain(handler, middlewares)
+}
+
+func (r *Router) Callback(prefix string, handler HandlerFunc, middlewares ...Middleware) {
+	r.callbacks[prefix] = chain(handler, middlewares)
+}
+
+// State регистрирует обработчик сообщений, которые не являются командами, от пользователей,
+// для которых active возвращает true. Такие сообщения не сравниваются с кнопками.
+func (r *Router) State(active func(userId int64) bool, handler HandlerFunc, middlewares ...Middleware) {
+	r.states = append(r.states, stateRoute{active: active, handler: chain(handler, middlewares)})
+}
+
+// Unknown - обработчик обновлений, для которых не нашлось маршрута.
+func (r *Router) Unknown(handler HandlerFunc) {
+	r.unknown = handler
+}
+
+// Route возвращает обработчик обновления без общих middleware.
+func (r *Router) Route(upd tgbotapi.Update) (HandlerFunc, bool) {
+	switch {
+	case upd.Message != nil && upd.Message.IsCommand():
+		handler, ok := r.commands[upd.Message.Command()]
+		return handler, ok
+
+	case upd.Message != nil:
+		for _, state := range r.states {
+			if upd.Message.From != nil && state.active(upd.Message.From.ID) {
+				return state.handler, true
+			}
+		}
+
+		key, ok := catalog.Match(upd.Message.Text, r.buttonKeys)
+		if !ok {
+			return nil, false
+		}
+		return r.buttons[key], true
+
+	case upd.CallbackQuery != nil:
+		// самый длинный префикс, чтобы "ls" не перехватывал более точные маршруты
+		data := upd.CallbackData()
+		var handler HandlerFunc
+		matched := -1
+		for prefix, prefixHandler := range r.callbacks {
+			if strings.HasPrefix(data, prefix) && len(prefix) > matched {
+				handler, matched = prefixHandler, len(prefix)
+			}
+		}
+		return handler, handler != nil
+	}
+
+	return nil, false
+}
+
+// Handle - HandlerFunc, который выбирает маршрут и пропускает обновление через общие middleware.
+func (r *Router) Handle(upd tgbotapi.Update, user *models.User) error {
+	handler, ok := r.Route(upd)
+	if !ok {
+		handler = r.unknown
+	}
+	return chain(handler, r.middlewares)(upd, user)
+}
+
+func chain(handler HandlerFunc, middlewares []Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recover не дает панике в обработчике одного обновления остановить бота,
+// паника возвращается как ошибка.
+func Recover(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, user *models.User) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic while handling update %d: %v\n%s", upd.UpdateID, r, debug.Stack())
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return next(upd, user)
+	}
+}
+
+// ReportErrors - граница обработки обновления пользователя: ошибку или панику обработчика
+// пользователь видит как сообщение о сбое, а администратор получает ее текст.
+func (b *TGBot) ReportErrors(next HandlerFunc) HandlerFunc {
+	next = Recover(next)
+	return func(upd tgbotapi.Update, user *models.User) error {
+		err := next(upd, user)
+		if err == nil {
+			return nil
+		}
+		log.Printf("error while handling update %d from user %d: %s", upd.UpdateID, user.Id, err)
+
+		lang := UserLang(user)
+		if IsAdmin(user.Id) {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ErrorAdminMessage, err)))
+			return nil
+		}
+
+		if upd.CallbackQuery != nil {
+			b.Request(tgbotapi.NewCallbackWithAlert(upd.CallbackQuery.ID, T(lang, ErrorUserMessage)))
+		} else {
+			b.trySend(tgbotapi.NewMessage(user.Id, T(lang, ErrorUserMessage)))
+		}
+		b.notifyAdmin(T(i18n.DefaultLang, UpdateErrorAdminMessage, user.Id, err))
+		return nil
+	}
+}
+
+func LogUpdate(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, user *models.User) error {
+		if upd.Message != nil {
+			log.Printf(
+				"received message update from ID %d with text %s",
+				upd.Message.From.ID,
+				upd.Message.Text,
+			)
+		} else if upd.CallbackQuery != nil {
+			log.Printf(
+				"received callback update from ID %d with data %s",
+				upd.CallbackQuery.From.ID,
+				upd.CallbackData(),
+			)
+		}
+		return next(upd, user)
+	}
+}
+
+func TimeUpdate(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, user *models.User) error {
+		startedAt := time.Now()
+		err := next(upd, user)
+		log.Printf("handled update %d in %s", upd.UpdateID, time.Since(startedAt))
+		return err
+	}
+}
+
+// LoadUser находит отправителя в базе. Новый пользователь регистрируется, если пишет
+// в личный чат, в группах кнопки нажимают и те, кто не писал боту лично.
+func (b *TGBot) LoadUser(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, _ *models.User) error {
+		from := upd.SentFrom()
+		if from == nil {
+			log.Println("update without sender, skip update")
+			return nil
+		}
+
+		user, err := b.Service.GetUserById(from.ID)
+		if err != nil {
+			if err != sqlite.ErrUserNotFound {
+				return fmt.Errorf("error while getting user %d: %w", from.ID, err)
+			}
+
+			user = &models.User{
+				Id:       from.ID,
+				Username: from.UserName,
+				Language: string(updateLang(upd)),
+			}
+			// незарегистрированные пользователи из групп доходят только до настроек
+			// рассылки чата, см. dispatch, и ничего не пишут в свои таблицы
+			if chat := upd.FromChat(); chat != nil && chat.IsPrivate() {
+				if err := b.registerUser(user); err != nil {
+					return err
+				}
+			}
+		}
+
+		// пользователи, пришедшие до появления переводов, получают язык из Telegram
+		if user.Language == "" {
+			user.Language = string(updateLang(upd))
+			if err := b.Service.SetUserLanguage(user.Id, user.Language); err != nil {
+				log.Printf("error while saving language of user %d: %s", user.Id, err)
+			}
+		}
+
+		return next(upd, user)
+	}
+}
+
+// AdminOnly пропускает только администратора, остальным на нажатие кнопки
+// отвечает пустым callback, а сообщения молча игнорирует.
+func (b *TGBot) AdminOnly(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, user *models.User) error {
+		if !IsAdmin(user.Id) {
+			if upd.CallbackQuery != nil {
+				b.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ""))
+			}
+			return nil
+		}
+		return next(upd, user)
+	}
+}
+
+// CleanupMessage удаляет сообщение пользователя, чтобы в чате оставались только ответы бота.
+// Сообщение удаляется после обработчика, чтобы он успел его скопировать, как черновик рассылки.
+func (b *TGBot) CleanupMessage(next HandlerFunc) HandlerFunc {
+	return func(upd tgbotapi.Update, user *models.User) error {
+		if upd.Message == nil {
+			return next(upd, user)
+		}
+
+		err := next(upd, user)
+		b.Send(tgbotapi.NewDeleteMessage(user.Id, upd.Message.MessageID))
+		return err
+	}
+}
+
+// withUpdate подходит для обработчиков обновлений без пользователя: inline-запросов и чатов.
+func withUpdate(handler func(upd tgbotapi.Update)) HandlerFunc {
+	return func(upd tgbotapi.Update, _ *models.User) error {
+		handler(upd)
+		return nil
+	}
+}
+
+// withUser подходит для обработчиков, которым из обновления нужен только пользователь.
+func withUser(handler func(user *models.User) error) HandlerFunc {
+	return func(_ tgbotapi.Update, user *models.User) error {
+		return handler(user)
+	}
+}
@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/i18n"
+	"hip-hop-geek/internal/models"
+)
+
+// stubUsersService не знает ни одного пользователя
+type stubUsersService struct {
+	HipHopService
+	addErr error
+	added  []models.User
+}
+
+func (s *stubUsersService) GetUserById(int64) (*models.User, error) {
+	return nil, sqlite.ErrUserNotFound
+}
+
+func (s *stubUsersService) AddUser(user models.User) error {
+	s.added = append(s.added, user)
+	return s.addErr
+}
+
+func TestRouter(t *testing.T) {
+	commandUpdate := func(text string) tgbotapi.Update {
+		return tgbotapi.Update{Message: &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(strings.Fields(text)[0])}},
+		}}
+	}
+	callbackUpdate := func(data string) tgbotapi.Update {
+		return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{Data: data}}
+	}
+
+	var handled []string
+	record := func(name string) HandlerFunc {
+		return func(tgbotapi.Update, *models.User) error {
+			handled = append(handled, name)
+			return nil
+		}
+	}
+	trace := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(upd tgbotapi.Update, user *models.User) error {
+				handled = append(handled, name)
+				return next(upd, user)
+			}
+		}
+	}
+
+	r := NewRouter()
+	r.Use(trace("first"), trace("second"))
+	r.Command(FollowCommandText, record("follow"), trace("route"))
+	r.Button(TodayButtonText, record("today"))
+	r.Callback(ListCallbackPrefix, record("list"))
+	r.Callback(LanguageCallbackPrefix, record("lang"))
+	r.Unknown(record("unknown"))
+
+	tests := []struct {
+		name     string
+		upd      tgbotapi.Update
+		expected []string
+	}{
+		{"command", commandUpdate("/follow Drake"), []string{"first", "second", "route", "follow"}},
+		{"button on any language", tgbotapi.Update{Message: &tgbotapi.Message{Text: T(i18n.En, TodayButtonText)}}, []string{"first", "second", "today"}},
+		{"callback prefix", callbackUpdate("lang:en"), []string{"first", "second", "lang"}},
+		{"callback list", callbackUpdate("ls:m:2024:1"), []string{"first", "second", "list"}},
+		{"unknown command", commandUpdate("/unknown"), []string{"first", "second", "unknown"}},
+		{"unknown callback", callbackUpdate("page:2"), []string{"first", "second", "unknown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = nil
+			assert.NoError(t, r.Handle(tt.upd, nil))
+			assert.Equal(t, tt.expected, handled)
+		})
+	}
+
+	t.Run("state before buttons", func(t *testing.T) {
+		waiting := map[int64]bool{42: true}
+		r := NewRouter()
+		r.State(func(userId int64) bool { return waiting[userId] }, record("draft"))
+		r.Command(FollowCommandText, record("follow"))
+		r.Button(TodayButtonText, record("today"))
+
+		message := func(userId int64, text string) tgbotapi.Update {
+			return tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: userId}, Text: text}}
+		}
+		handled = nil
+		assert.NoError(t, r.Handle(message(42, T(i18n.Ru, TodayButtonText)), nil))
+		assert.NoError(t, r.Handle(message(7, T(i18n.Ru, TodayButtonText)), nil))
+		command := commandUpdate("/follow Drake")
+		command.Message.From = &tgbotapi.User{ID: 42}
+		assert.NoError(t, r.Handle(command, nil))
+		assert.Equal(t, []string{"draft", "today", "follow"}, handled)
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		t.Setenv("ADMIN_ID", "42")
+		b := &TGBot{}
+		handled = nil
+		admin := b.AdminOnly(record("admin"))
+
+		assert.NoError(t, admin(tgbotapi.Update{Message: &tgbotapi.Message{Text: "/stats"}}, &models.User{Id: 7}))
+		assert.Empty(t, handled)
+		assert.NoError(t, admin(tgbotapi.Update{Message: &tgbotapi.Message{Text: "/stats"}}, &models.User{Id: 42}))
+		assert.Equal(t, []string{"admin"}, handled)
+
+		t.Setenv("ADMIN_ID", "")
+		assert.False(t, IsAdmin(0))
+	})
+
+	t.Run("recover from panic", func(t *testing.T) {
+		handler := Recover(func(tgbotapi.Update, *models.User) error { panic("boom") })
+		var err error
+		assert.NotPanics(t, func() { err = handler(tgbotapi.Update{}, nil) })
+		assert.EqualError(t, err, "panic: boom")
+	})
+
+	t.Run("report errors", func(t *testing.T) {
+		t.Setenv("ADMIN_ID", "42")
+		api := &stubRequester{}
+		b := &TGBot{}
+		b.sender, _ = newTestSender(api)
+		handler := b.ReportErrors(func(tgbotapi.Update, *models.User) error { panic("boom") })
+
+		user := &models.User{Id: 7, Language: string(i18n.En)}
+		assert.NoError(t, handler(tgbotapi.Update{Message: &tgbotapi.Message{Text: "/follow"}}, user))
+		if assert.Len(t, api.sent, 2) {
+			toUser := api.sent[0].(tgbotapi.MessageConfig)
+			assert.Equal(t, int64(7), toUser.ChatID)
+			assert.Equal(t, T(i18n.En, ErrorUserMessage), toUser.Text)
+
+			toAdmin := api.sent[1].(tgbotapi.MessageConfig)
+			assert.Equal(t, int64(42), toAdmin.ChatID)
+			assert.Contains(t, toAdmin.Text, "panic: boom")
+		}
+
+		// администратор получает текст ошибки сразу, без второго сообщения
+		api.sent = nil
+		handler = b.ReportErrors(func(tgbotapi.Update, *models.User) error { return errors.New("boom") })
+		assert.NoError(t, handler(tgbotapi.Update{Message: &tgbotapi.Message{Text: "/stats"}}, &models.User{Id: 42}))
+		if assert.Len(t, api.sent, 1) {
+			assert.Equal(t, T(i18n.DefaultLang, ErrorAdminMessage, "boom"), api.sent[0].(tgbotapi.MessageConfig).Text)
+		}
+	})
+
+	t.Run("load user", func(t *testing.T) {
+		service := &stubUsersService{addErr: errors.New("disk is full")}
+		b := &TGBot{Service: service}
+		handled = nil
+		load := b.LoadUser(record("handler"))
+
+		callback := func(chatType string) tgbotapi.Update {
+			return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				From:    &tgbotapi.User{ID: 7},
+				Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100, Type: chatType}},
+			}}
+		}
+
+		// без записи в users нельзя сохранять отслеживания и списки
+		assert.Error(t, load(callback("private"), nil))
+		assert.Empty(t, handled)
+
+		// из группы пользователь не регистрируется
+		service.added = nil
+		assert.NoError(t, load(callback("group"), nil))
+		assert.Empty(t, service.added)
+		assert.Equal(t, []string{"handler"}, handled)
+	})
+
+	t.Run("errors reach the caller", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		r := NewRouter()
+		r.Use(Recover, TimeUpdate)
+		r.Callback(ListCallbackPrefix, func(tgbotapi.Update, *models.User) error { return errBoom })
+
+		assert.ErrorIs(t, r.Handle(callbackUpdate("ls:m:2024:1"), nil), errBoom)
+	})
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// Telegram разрешает боту около 30 сообщений в секунду, оставляем запас
+	SenderMessagesPerSecond = 25
+	// в личный чат - не чаще сообщения в секунду, в группу или канал - 20 сообщений в минуту
+	ChatMessageInterval  = time.Second
+	GroupMessageInterval = 3 * time.Second
+
+	SenderMaxRetries = 3
+	SenderRetryDelay = time.Second
+
+	// после стольких чатов очередь забывает тех, кому давно ничего не отправляла
+	senderCleanupSize = 10000
+)
+
+// SendOutcome - итог отправки сообщения для отчетов рассылок.
+type SendOutcome int
+
+const (
+	Delivered SendOutcome = iota
+	Blocked
+	Failed
+)
+
+// Outcome разбирает ошибку отправки: 403 - пользователь заблокировал бота или бота удалили из чата.
+func Outcome(err error) SendOutcome {
+	if err == nil {
+		return Delivered
+	}
+
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+		return Blocked
+	}
+	return Failed
+}
+
+type Requester interface {
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// Sender - общая очередь исходящих запросов. Каждый запрос в чат занимает слот в общем
+// лимите бота, новые сообщения - еще и в лимите чата, ошибки 429 и временные сбои повторяются.
+// Правки и удаления сообщений лимит чата не занимают, иначе ответ на кнопку ждал бы
+// удаления сообщения пользователя. Запросы без чата (ответы на кнопки и inline-запросы)
+// отправляются сразу.
+type Sender struct {
+	api Requester
+
+	mu         sync.Mutex
+	nextGlobal time.Time
+	nextByChat map[int64]time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewSender(api Requester) *Sender {
+	return &Sender{
+		api:        api,
+		nextByChat: make(map[int64]time.Time),
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// Request ждет своей очереди и отправляет запрос, ошибка возвращается вызывающему.
+func (s *Sender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatId := chatIdOf(c)
+	if chatId == 0 {
+		return s.api.Request(c)
+	}
+
+	paced := isNewMessage(c)
+	for attempt := 0; ; attempt++ {
+		// общий слот занимаем только после ожидания чата, иначе медленный чат задержит остальных
+		if paced {
+			s.sleep(s.reserveChat(chatId))
+		}
+		s.sleep(s.reserveGlobal())
+		resp, err := s.api.Request(c)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay, retry := retryDelay(err, attempt)
+		if !retry {
+			return resp, err
+		}
+		log.Printf("error while sending to chat %d, retry in %s: %s", chatId, delay, err)
+		s.pause(chatId, delay)
+		if !paced {
+			s.sleep(delay)
+		}
+	}
+}
+
+// reserveChat занимает ближайший слот чата и возвращает, сколько до него ждать.
+func (s *Sender) reserveChat(chatId int64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.nextByChat) > senderCleanupSize {
+		for id, next := range s.nextByChat {
+			if next.Before(now) {
+				delete(s.nextByChat, id)
+			}
+		}
+	}
+
+	slot := now
+	if next := s.nextByChat[chatId]; next.After(slot) {
+		slot = next
+	}
+	s.nextByChat[chatId] = slot.Add(chatInterval(chatId))
+	return slot.Sub(now)
+}
+
+// reserveGlobal занимает ближайший слот в общем лимите бота.
+func (s *Sender) reserveGlobal() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	slot := now
+	if s.nextGlobal.After(slot) {
+		slot = s.nextGlobal
+	}
+	s.nextGlobal = slot.Add(time.Second / SenderMessagesPerSecond)
+	return slot.Sub(now)
+}
+
+// pause откладывает следующие запросы в чат, например на retry_after из ответа 429.
+func (s *Sender) pause(chatId int64, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until := s.now().Add(delay); until.After(s.nextByChat[chatId]) {
+		s.nextByChat[chatId] = until
+	}
+}
+
+// retryDelay решает, повторять ли запрос: 429 - через retry_after,
+// сетевые ошибки и 5xx - с растущей задержкой, остальные ошибки не повторяются.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	if attempt >= SenderMaxRetries {
+		return 0, false
+	}
+
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return SenderRetryDelay << attempt, true
+	}
+	if tgErr.RetryAfter > 0 {
+		return time.Duration(tgErr.RetryAfter) * time.Second, true
+	}
+	if tgErr.Code >= http.StatusInternalServerError {
+		return SenderRetryDelay << attempt, true
+	}
+	return 0, false
+}
+
+// у групп и каналов id отрицательные
+func chatInterval(chatId int64) time.Duration {
+	if chatId < 0 {
+		return GroupMessageInterval
+	}
+	return ChatMessageInterval
+}
+
+// isNewMessage - запрос отправляет в чат новое сообщение: конфиги сообщений встраивают
+// BaseChat, у альбома поля свои. Правки встраивают BaseEdit, удаление - отдельный конфиг.
+func isNewMessage(c tgbotapi.Chattable) bool {
+	if _, ok := c.(tgbotapi.MediaGroupConfig); ok {
+		return true
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(c))
+	if value.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := value.Type().FieldByName("BaseChat")
+	return ok && field.Anonymous
+}
+
+// chatIdOf достает чат из конфига запроса. Параметры Chattable в библиотеке
+// закрыты, поэтому ищем поле ChatID, которое есть в BaseChat и BaseEdit.
+func chatIdOf(c tgbotapi.Chattable) int64 {
+	value := reflect.Indirect(reflect.ValueOf(c))
+	if value.Kind() != reflect.Struct {
+		return 0
+	}
+
+	field := value.FieldByName("ChatID")
+	if !field.IsValid() || field.Kind() != reflect.Int64 {
+		return 0
+	}
+	return field.Int()
+}
+
+// Request, Send, CopyMessage и SendMediaGroup перекрывают методы BotAPI,
+// чтобы все исходящие сообщения бота проходили через очередь.
+// Пользователь, заблокировавший бота, перестает получать рассылки, пока не вернется.
+func (b *TGBot) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	resp, err := b.sender.Request(c)
+	if chatId := chatIdOf(c); chatId > 0 && Outcome(err) == Blocked {
+		log.Printf("user %d blocked the bot: %s", chatId, err)
+		if err := b.Service.SetUserActive(chatId, false); err != nil {
+			log.Printf("error while deactivating user %d: %s", chatId, err)
+		}
+	}
+	return resp, err
+}
+
+func (b *TGBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	resp, err := b.Request(c)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	var message tgbotapi.Message
+	err = json.Unmarshal(resp.Result, &message)
+	return message, err
+}
+
+func (b *TGBot) CopyMessage(config tgbotapi.CopyMessageConfig) (tgbotapi.MessageID, error) {
+	resp, err := b.Request(config)
+	if err != nil {
+		return tgbotapi.MessageID{}, err
+	}
+
+	var messageId tgbotapi.MessageID
+	err = json.Unmarshal(resp.Result, &messageId)
+	return messageId, err
+}
+
+func (b *TGBot) SendMediaGroup(config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	resp, err := b.Request(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []tgbotapi.Message
+	err = json.Unmarshal(resp.Result, &messages)
+	return messages, err
+}
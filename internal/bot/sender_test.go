@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRequester struct {
+	errs   []error
+	result []byte
+	calls  int
+	sent   []tgbotapi.Chattable
+}
+
+func (r *stubRequester) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	r.calls++
+	r.sent = append(r.sent, c)
+	if len(r.errs) != 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		if err != nil {
+			return &tgbotapi.APIResponse{}, err
+		}
+	}
+	if r.result != nil {
+		return &tgbotapi.APIResponse{Ok: true, Result: r.result}, nil
+	}
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("{}")}, nil
+}
+
+func newTestSender(api Requester) (*Sender, *[]time.Duration) {
+	now := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	sender := NewSender(api)
+	sender.now = func() time.Time { return now }
+	sender.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		now = now.Add(d)
+	}
+	return sender, &waits
+}
+
+func TestSender(t *testing.T) {
+	t.Run("global limit", func(t *testing.T) {
+		sender, _ := newTestSender(&stubRequester{})
+
+		assert.Equal(t, time.Duration(0), sender.reserveGlobal())
+		assert.Equal(t, time.Second/SenderMessagesPerSecond, sender.reserveGlobal())
+		assert.Equal(t, 2*time.Second/SenderMessagesPerSecond, sender.reserveGlobal())
+	})
+
+	t.Run("chat limits", func(t *testing.T) {
+		sender, _ := newTestSender(&stubRequester{})
+
+		assert.Equal(t, time.Duration(0), sender.reserveChat(1))
+		assert.Equal(t, time.Duration(0), sender.reserveChat(2))
+		assert.Equal(t, ChatMessageInterval, sender.reserveChat(1))
+		assert.Equal(t, 2*ChatMessageInterval, sender.reserveChat(1))
+
+		assert.Equal(t, time.Duration(0), sender.reserveChat(-100))
+		assert.Equal(t, GroupMessageInterval, sender.reserveChat(-100))
+	})
+
+	t.Run("messages to one chat are spaced", func(t *testing.T) {
+		sender, waits := newTestSender(&stubRequester{})
+
+		for i := 0; i < 2; i++ {
+			_, err := sender.Request(tgbotapi.NewMessage(1, "hi"))
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []time.Duration{0, 0, ChatMessageInterval, 0}, *waits)
+	})
+
+	t.Run("deletes and edits skip chat limit", func(t *testing.T) {
+		sender, waits := newTestSender(&stubRequester{})
+
+		_, err := sender.Request(tgbotapi.NewDeleteMessage(1, 10))
+		assert.NoError(t, err)
+		_, err = sender.Request(tgbotapi.NewMessage(1, "hi"))
+		assert.NoError(t, err)
+		_, err = sender.Request(tgbotapi.NewEditMessageText(1, 11, "page 2"))
+		assert.NoError(t, err)
+		for _, wait := range *waits {
+			assert.Less(t, wait, ChatMessageInterval)
+		}
+
+		assert.True(t, isNewMessage(tgbotapi.NewPhoto(1, tgbotapi.FileURL("cover"))))
+		assert.True(t, isNewMessage(tgbotapi.NewCopyMessage(1, 2, 3)))
+		assert.True(t, isNewMessage(tgbotapi.NewMediaGroup(1, nil)))
+		assert.False(t, isNewMessage(tgbotapi.NewEditMessageReplyMarkup(1, 2, tgbotapi.InlineKeyboardMarkup{})))
+	})
+
+	t.Run("retry after 429", func(t *testing.T) {
+		api := &stubRequester{errs: []error{
+			&tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}},
+		}}
+		sender, waits := newTestSender(api)
+
+		_, err := sender.Request(tgbotapi.NewMessage(1, "hi"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, api.calls)
+		assert.Equal(t, []time.Duration{0, 0, 5 * time.Second, 0}, *waits)
+	})
+
+	t.Run("transient errors are retried, others returned", func(t *testing.T) {
+		api := &stubRequester{errs: []error{
+			errors.New("connection reset"),
+			&tgbotapi.Error{Code: 502, Message: "Bad Gateway"},
+			&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"},
+		}}
+		sender, _ := newTestSender(api)
+
+		_, err := sender.Request(tgbotapi.NewMessage(1, "hi"))
+		assert.Equal(t, 3, api.calls)
+		assert.Equal(t, Blocked, Outcome(err))
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		api := &stubRequester{errs: []error{
+			errors.New("timeout"), errors.New("timeout"), errors.New("timeout"), errors.New("timeout"),
+		}}
+		sender, _ := newTestSender(api)
+
+		_, err := sender.Request(tgbotapi.NewMessage(1, "hi"))
+		assert.Equal(t, SenderMaxRetries+1, api.calls)
+		assert.Equal(t, Failed, Outcome(err))
+	})
+
+	t.Run("requests without chat are not queued", func(t *testing.T) {
+		sender, waits := newTestSender(&stubRequester{})
+
+		_, err := sender.Request(tgbotapi.NewCallback("query", ""))
+		assert.NoError(t, err)
+		assert.Empty(t, *waits)
+		assert.Equal(t, int64(7), chatIdOf(tgbotapi.NewEditMessageText(7, 1, "edit")))
+		assert.Equal(t, int64(7), chatIdOf(tgbotapi.NewCopyMessage(7, 1, 1)))
+	})
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	DefaultWebhookListen = ":8080"
+
+	// сколько обновлений ждут разбора, пока Telegram получает ответ на запрос вебхука
+	webhookUpdatesBuffer   = 100
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// WebhookConfig - настройки приема обновлений через вебхук.
+//
+// URL - публичный адрес бота, например https://example.com/hiphop: Telegram шлет обновления
+// на URL/Secret, и встроенный сервер ждет их по тому же пути. Если бот стоит за reverse proxy,
+// TLS обычно завершается на нем, иначе CertFile и KeyFile включают HTTPS на самом сервере,
+// а сертификат загружается в Telegram (это нужно для самоподписанного).
+// Пустой Listen - DefaultWebhookListen.
+type WebhookConfig struct {
+	URL      string
+	Listen   string
+	Secret   string
+	CertFile string
+	KeyFile  string
+}
+
+// Enabled - вебхук включается, если задан публичный адрес, иначе бот работает через long polling.
+func (c WebhookConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// Link возвращает адрес вебхука с секретом в пути.
+func (c WebhookConfig) Link() (*url.URL, error) {
+	link, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url %q: %w", c.URL, err)
+	}
+	if link.Scheme != "https" {
+		return nil, fmt.Errorf("webhook url %q must be https", c.URL)
+	}
+	if c.Secret == "" {
+		return nil, errors.New("webhook secret is empty")
+	}
+
+	return link.JoinPath(c.Secret), nil
+}
+
+// StartWebhook регистрирует вебхук в Telegram и принимает обновления встроенным
+// HTTP сервером до отмены контекста. Обновления разбираются так же, как при long polling.
+func (b *TGBot) StartWebhook(ctx context.Context, config WebhookConfig) error {
+	link, err := config.Link()
+	if err != nil {
+		return err
+	}
+
+	webhook := tgbotapi.WebhookConfig{URL: link}
+	if config.CertFile != "" {
+		webhook.Certificate = tgbotapi.FilePath(config.CertFile)
+	}
+	if _, err := b.Request(webhook); err != nil {
+		return fmt.Errorf("error while setting webhook: %w", err)
+	}
+
+	updates := make(chan tgbotapi.Update, webhookUpdatesBuffer)
+	mux := http.NewServeMux()
+	mux.Handle(link.Path, b.webhookHandler(ctx, updates))
+	if config.Listen == "" {
+		config.Listen = DefaultWebhookListen
+	}
+	server := &http.Server{Addr: config.Listen, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("error while stopping webhook server: %s", err)
+		}
+	}()
+	go b.serve(ctx, updates)
+
+	log.Printf("bot start webhook server on %s...", config.Listen)
+	if config.CertFile != "" && config.KeyFile != "" {
+		err = server.ListenAndServeTLS(config.CertFile, config.KeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// webhookHandler отвечает Telegram сразу, как только обновление попало в очередь разбора.
+func (b *TGBot) webhookHandler(ctx context.Context, updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upd, err := b.HandleUpdate(r)
+		if err != nil {
+			log.Printf("error while reading webhook update: %s", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- *upd:
+		case <-ctx.Done():
+			// бот останавливается, Telegram повторит обновление после перезапуска
+			http.Error(w, "bot is stopping", http.StatusServiceUnavailable)
+		}
+	})
+}
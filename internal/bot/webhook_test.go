@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Run("link with secret", func(t *testing.T) {
+		config := WebhookConfig{URL: "https://example.com/hiphop", Secret: "s3cr3t"}
+		link, err := config.Link()
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/hiphop/s3cr3t", link.String())
+		assert.Equal(t, "/hiphop/s3cr3t", link.Path)
+		assert.True(t, config.Enabled())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := WebhookConfig{URL: "http://example.com", Secret: "s3cr3t"}.Link()
+		assert.Error(t, err)
+		_, err = WebhookConfig{URL: "https://example.com"}.Link()
+		assert.Error(t, err)
+		assert.False(t, WebhookConfig{}.Enabled())
+	})
+
+	t.Run("handler queues updates", func(t *testing.T) {
+		b := &TGBot{}
+		updates := make(chan tgbotapi.Update, 1)
+		handler := b.webhookHandler(context.Background(), updates)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(
+			http.MethodPost, "/hiphop/s3cr3t", strings.NewReader(`{"update_id": 42}`),
+		))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, 42, (<-updates).UpdateID)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/hiphop/s3cr3t", nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("handler stops with bot", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		handler := (&TGBot{}).webhookHandler(ctx, make(chan tgbotapi.Update))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(
+			http.MethodPost, "/hiphop/s3cr3t", strings.NewReader(`{"update_id": 42}`),
+		))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
@@ -0,0 +1,76 @@
+-- Full-text index over artist names and release titles, rowid = releases.release_id.
+-- FTS5 is compiled into go-sqlite3 only with the sqlite_fts5 build tag, see Makefile and Dockerfile.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS releases_search USING fts5(
+    artist,
+    title,
+    tokenize = 'unicode61'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO releases_search (rowid, artist, title)
+SELECT r.release_id, a.name, r.title
+FROM releases AS r
+JOIN artists AS a ON r.artist_id = a.artist_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS releases_search_insert AFTER INSERT ON releases
+BEGIN
+    INSERT INTO releases_search (rowid, artist, title)
+    SELECT NEW.release_id, a.name, NEW.title
+    FROM artists AS a
+    WHERE a.artist_id = NEW.artist_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS releases_search_delete AFTER DELETE ON releases
+BEGIN
+    DELETE FROM releases_search WHERE rowid = OLD.release_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS releases_search_update AFTER UPDATE OF title, artist_id ON releases
+BEGIN
+    DELETE FROM releases_search WHERE rowid = OLD.release_id;
+    INSERT INTO releases_search (rowid, artist, title)
+    SELECT NEW.release_id, a.name, NEW.title
+    FROM artists AS a
+    WHERE a.artist_id = NEW.artist_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS releases_search_artist_update AFTER UPDATE OF name ON artists
+BEGIN
+    UPDATE releases_search
+    SET artist = NEW.name
+    WHERE rowid IN (SELECT release_id FROM releases WHERE artist_id = NEW.artist_id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS releases_search_artist_update;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS releases_search_update;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS releases_search_delete;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS releases_search_insert;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS releases_search;
+-- +goose StatementEnd
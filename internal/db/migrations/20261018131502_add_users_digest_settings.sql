@@ -0,0 +1,27 @@
+-- Empty timezone and digest_time mean that server defaults are used.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN digest_time TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN last_digest_date TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN last_digest_date;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN digest_time;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- Replaces users.today_subscribe with one row per user and subscription kind.
+-- Old subscribers keep both daily posts, everyone keeps followed-artist alerts.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    PRIMARY KEY (user_id, kind),
+    FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE
+        ON UPDATE NO ACTION
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO subscriptions (user_id, kind)
+SELECT id, 'history' FROM users WHERE today_subscribe = true;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO subscriptions (user_id, kind)
+SELECT id, 'today_releases' FROM users WHERE today_subscribe = true;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO subscriptions (user_id, kind)
+SELECT id, 'artist_alerts' FROM users;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN today_subscribe;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN today_subscribe BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE users SET today_subscribe = true
+WHERE id IN (SELECT user_id FROM subscriptions WHERE kind = 'history');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscriptions;
+-- +goose StatementEnd
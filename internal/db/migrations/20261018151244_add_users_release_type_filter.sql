@@ -0,0 +1,11 @@
+-- 0 means that lists show releases of any type.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN release_type_filter INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN release_type_filter;
+-- +goose StatementEnd
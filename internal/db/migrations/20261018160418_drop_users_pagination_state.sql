@@ -0,0 +1,29 @@
+-- Pagination state now lives in the callback data of list messages.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN releases_message_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN releases_page_count;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN today_releases_message_id;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN today_releases_page_count;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN releases_message_id INTEGER;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN releases_page_count INTEGER;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN today_releases_message_id INTEGER;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN today_releases_page_count INTEGER;
+-- +goose StatementEnd
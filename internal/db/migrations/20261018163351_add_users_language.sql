@@ -0,0 +1,11 @@
+-- Empty language means that it is taken from Telegram language_code on the next update.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN language TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN language;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- Groups and channels that receive digests, with settings separate from users.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chats (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    digest_time TEXT NOT NULL DEFAULT '',
+    last_digest_date TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT ''
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chat_subscriptions (
+    chat_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    PRIMARY KEY (chat_id, kind),
+    FOREIGN KEY (chat_id)
+        REFERENCES chats (id)
+        ON DELETE CASCADE
+        ON UPDATE NO ACTION
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_subscriptions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chats;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- Posts auto-published to channels, so a restart does not publish them twice.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS publications (
+    channel_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    date TEXT NOT NULL,
+    message_id INTEGER NOT NULL DEFAULT 0,
+    published_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (channel_id, kind, date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS publications;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- Releases deleted by the admin, so the updater does not fetch them from HipHopDX again.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS deleted_releases (
+    release_id INTEGER PRIMARY KEY,
+    deleted_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS deleted_releases;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- Users who blocked the bot are kept but get no messages until they send /start again.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN is_active INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN is_active;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- Reminders about upcoming releases, the release date is always taken from the releases table.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminders (
+    user_id INTEGER NOT NULL,
+    release_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, release_id),
+    FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE
+        ON UPDATE NO ACTION,
+    FOREIGN KEY (release_id)
+        REFERENCES releases (release_id)
+        ON DELETE CASCADE
+        ON UPDATE NO ACTION
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminders;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- Release dates fixed by the admin are not overwritten by the HipHopDX date on refresh.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE releases ADD COLUMN date_overridden INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE releases DROP COLUMN date_overridden;
+-- +goose StatementEnd
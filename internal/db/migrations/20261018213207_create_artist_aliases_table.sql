@@ -0,0 +1,18 @@
+-- Names of merged duplicate artists, so a refresh from HipHopDX finds the merged artist instead of recreating the duplicate.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS artist_aliases (
+    name TEXT PRIMARY KEY,
+    artist_id INTEGER NOT NULL,
+    FOREIGN KEY (artist_id)
+        REFERENCES artists (artist_id)
+        ON DELETE CASCADE
+        ON UPDATE NO ACTION
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS artist_aliases;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- Date of the last failed reminder send, a failed reminder is retried only on the next day.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders ADD COLUMN last_attempt_date TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders DROP COLUMN last_attempt_date;
+-- +goose StatementEnd
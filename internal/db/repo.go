@@ -10,32 +10,120 @@ type ReleaseRepositoryInterface interface {
 	AddRelease(release models.Release, artId int) (int, error)
 	GetReleaseById(id int) (*ReleaseDB, error)
 	GetReleaseByTitle(title string) (*ReleaseDB, error)
-	GetReleasesByMonth(month time.Month, year, limit, offset int) ([]*ReleaseDB, error)
+	GetReleasesByMonth(
+		month time.Month,
+		year int,
+		releaseType models.ReleaseType,
+		limit, offset int,
+	) ([]*ReleaseDB, error)
 	GetReleasesByYear(year, limit, offset int) ([]*ReleaseDB, error)
-	GetReleasesByDay(year int, month time.Month, day, limit, offset int) ([]*ReleaseDB, error)
+	GetReleasesByDay(
+		year int,
+		month time.Month,
+		day int,
+		releaseType models.ReleaseType,
+		limit, offset int,
+	) ([]*ReleaseDB, error)
+	GetReleasesByPeriod(from, to time.Time, limit, offset int) ([]*ReleaseDB, error)
 	GetReleasesWithoutCover() ([]*ReleaseDB, error)
+	GetReleaseYears() ([]int, error)
+	SearchReleases(query string, limit, offset int) ([]*ReleaseDB, error)
 	UpdateReleaseCoverUrl(releaseId int, coverUrl string) error
+	AddManualRelease(release models.Release, artId int) (int, error)
+	UpdateReleaseTitle(releaseId int, title string) error
+	UpdateReleaseArtist(releaseId int, artId int) error
+	UpdateReleaseType(releaseId int, releaseType models.ReleaseType) error
+	UpdateReleaseDate(releaseId int, date time.Time) error
+	SyncReleaseDate(releaseId int, date time.Time) (bool, error)
+	DeleteRelease(releaseId int) error
 	CloseReleaseRepo()
 }
 
 type ArtistsRepositoryInterface interface {
 	AddArtist(artistName string) (int, error)
 	GetArtistByName(artistName string) (*ArtistDB, error)
+	FindArtistByName(artistName string) (*ArtistDB, error)
 	GetArtistById(id int) (*ArtistDB, error)
+	MergeArtists(fromId, toId int) error
 	CloseArtistRepo()
 }
 
 type UsersRepositoryInterface interface {
 	AddUser(user models.User) error
-	GetAllSubscribers() ([]*models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
-	SetTodaySubscribe(userId int64, isSubscribe bool) error
-	SetUserState(userId int64, messageType, messageId int, pageCount int) error
+	GetUserById(userId int64) (*models.User, error)
+	GetUsers() ([]*models.User, error)
+	SetUserTimezone(userId int64, timezone string) error
+	SetUserDigestTime(userId int64, digestTime string) error
+	SetLastDigestDate(userId int64, date string) error
+	SetUserReleaseTypeFilter(userId int64, releaseType models.ReleaseType) error
+	SetUserLanguage(userId int64, language string) error
+	SetUserActive(userId int64, isActive bool) error
+}
+
+type FollowsRepositoryInterface interface {
+	FollowArtist(userId int64, artistId int) error
+	UnfollowArtist(userId int64, artistId int) error
+	GetFollowedArtists(userId int64) ([]*ArtistDB, error)
+	GetArtistFollowers(artistId int) ([]*models.User, error)
+}
+
+type SubscriptionsRepositoryInterface interface {
+	Subscribe(userId int64, kind models.SubscriptionKind) error
+	Unsubscribe(userId int64, kind models.SubscriptionKind) error
+	GetUserSubscriptions(userId int64) ([]models.SubscriptionKind, error)
+	GetSubscribers(kind models.SubscriptionKind) ([]*models.User, error)
+}
+
+type ChatsRepositoryInterface interface {
+	AddChat(chat models.Chat) error
+	GetChat(chatId int64) (*models.Chat, error)
+	DeleteChat(chatId int64) error
+	SetChatTimezone(chatId int64, timezone string) error
+	SetChatDigestTime(chatId int64, digestTime string) error
+	SetChatLastDigestDate(chatId int64, date string) error
+	SubscribeChat(chatId int64, kind models.SubscriptionKind) error
+	UnsubscribeChat(chatId int64, kind models.SubscriptionKind) error
+	GetChatSubscriptions(chatId int64) ([]models.SubscriptionKind, error)
+	GetSubscribedChats(kind models.SubscriptionKind) ([]*models.Chat, error)
+}
+
+type PublicationsRepositoryInterface interface {
+	AddPublication(publication models.Publication) error
+	IsPublished(channelId int64, kind models.SubscriptionKind, date string) (bool, error)
+}
+
+type RemindersRepositoryInterface interface {
+	AddReminder(userId int64, releaseId int) error
+	DeleteReminder(userId int64, releaseId int) error
+	SetReminderAttemptDate(userId int64, releaseId int, date string) error
+	HasReminder(userId int64, releaseId int) (bool, error)
+	GetDueReminders(until time.Time) ([]models.Reminder, error)
+}
+
+type SavedReleasesRepositoryInterface interface {
+	SaveRelease(userId int64, releaseId int) error
+	UnsaveRelease(userId int64, releaseId int) error
+	IsReleaseSaved(userId int64, releaseId int) (bool, error)
+	GetSavedReleases(userId int64, byDate bool, limit, offset int) ([]*ReleaseDB, error)
+}
+
+type StatsRepositoryInterface interface {
+	AddUpdaterRun(run models.UpdaterRun) error
+	AddDigestRun(run models.DigestRun) error
+	GetStats() (*models.Stats, error)
 }
 
 type DbRepository interface {
 	ReleaseRepositoryInterface
 	ArtistsRepositoryInterface
 	UsersRepositoryInterface
+	FollowsRepositoryInterface
+	SubscriptionsRepositoryInterface
+	ChatsRepositoryInterface
+	PublicationsRepositoryInterface
+	RemindersRepositoryInterface
+	SavedReleasesRepositoryInterface
+	StatsRepositoryInterface
 	Close()
 }
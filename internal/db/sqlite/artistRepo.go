@@ -13,9 +13,39 @@ import (
 var _ db.ArtistsRepositoryInterface = (*ArtistSqliteRepo)(nil)
 
 const (
-	createArtistStmt     = `INSERT INTO artists (name) VALUES(?);`
-	getArtistByIdQuery   = `SELECT * FROM artists WHERE artist_id = ?;`
-	getArtistByNameQuery = `SELECT * FROM artists WHERE name = ?;`
+	createArtistStmt   = `INSERT INTO artists (name) VALUES(?);`
+	getArtistByIdQuery = `SELECT * FROM artists WHERE artist_id = ?;`
+
+	// имя дубликата после /mergeartists ведет к исполнителю, с которым его объединили
+	getArtistByNameQuery = `
+    SELECT * FROM artists WHERE artist_id = COALESCE(
+        (SELECT artist_id FROM artists WHERE name = ?),
+        (SELECT artist_id FROM artist_aliases WHERE name = ?)
+    );`
+
+	findArtistByNameQuery = `
+    SELECT * FROM artists WHERE artist_id = COALESCE(
+        (SELECT artist_id FROM artists WHERE name = ? COLLATE NOCASE),
+        (SELECT artist_id FROM artist_aliases WHERE name = ? COLLATE NOCASE)
+    );`
+
+	moveArtistReleasesStmt = `UPDATE releases SET artist_id = ? WHERE artist_id = ?;`
+
+	moveArtistFollowsStmt = `
+    INSERT OR IGNORE INTO follows (user_id, artist_id)
+    SELECT user_id, ? FROM follows WHERE artist_id = ?;
+    `
+
+	deleteArtistFollowsStmt = `DELETE FROM follows WHERE artist_id = ?;`
+
+	addArtistAliasStmt = `
+    INSERT OR REPLACE INTO artist_aliases (name, artist_id)
+    SELECT name, ? FROM artists WHERE artist_id = ?;
+    `
+
+	moveArtistAliasesStmt = `UPDATE artist_aliases SET artist_id = ? WHERE artist_id = ?;`
+
+	deleteArtistStmt = `DELETE FROM artists WHERE artist_id = ?;`
 )
 
 type ArtistSqlite struct {
@@ -53,7 +83,7 @@ func (a *ArtistSqliteRepo) AddArtist(artistName string) (int, error) {
 
 func (a *ArtistSqliteRepo) GetArtistByName(artistName string) (*db.ArtistDB, error) {
 	var artist []ArtistSqlite
-	err := a.DB.Select(&artist, getArtistByNameQuery, artistName)
+	err := a.DB.Select(&artist, getArtistByNameQuery, artistName, artistName)
 	if err != nil {
 		return nil, fmt.Errorf("error while querying artist by name: %s", err)
 	}
@@ -70,6 +100,25 @@ func (a *ArtistSqliteRepo) GetArtistByName(artistName string) (*db.ArtistDB, err
 	}, nil
 }
 
+// FindArtistByName ищет исполнителя по имени без учета регистра,
+// используется для поиска по тексту, введенному пользователем.
+func (a *ArtistSqliteRepo) FindArtistByName(artistName string) (*db.ArtistDB, error) {
+	var artist []ArtistSqlite
+	err := a.DB.Select(&artist, findArtistByNameQuery, artistName, artistName)
+	if err != nil {
+		return nil, fmt.Errorf("error while finding artist by name: %w", err)
+	}
+
+	if len(artist) == 0 {
+		return nil, ErrArtistNotFound
+	}
+
+	return &db.ArtistDB{
+		Id:   artist[0].Id,
+		Name: artist[0].Name,
+	}, nil
+}
+
 func (a *ArtistSqliteRepo) GetArtistById(id int) (*db.ArtistDB, error) {
 	var artist []ArtistSqlite
 	err := a.DB.Select(&artist, getArtistByIdQuery, id)
@@ -89,6 +138,47 @@ func (a *ArtistSqliteRepo) GetArtistById(id int) (*db.ArtistDB, error) {
 	}, nil
 }
 
+// MergeArtists переносит релизы и подписчиков дубликата fromId к исполнителю toId
+// и удаляет дубликат. Имя дубликата остается псевдонимом toId, чтобы обновление
+// релизов не создало дубликат снова.
+func (a *ArtistSqliteRepo) MergeArtists(fromId, toId int) error {
+	if fromId == toId {
+		return fmt.Errorf("can't merge artist %d with itself", fromId)
+	}
+
+	tx, err := a.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error while starting merge artists transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(moveArtistReleasesStmt, toId, fromId); err != nil {
+		return fmt.Errorf("db error move releases of artist %d: %w", fromId, err)
+	}
+	if _, err = tx.Exec(moveArtistFollowsStmt, toId, fromId); err != nil {
+		return fmt.Errorf("db error move followers of artist %d: %w", fromId, err)
+	}
+	if _, err = tx.Exec(deleteArtistFollowsStmt, fromId); err != nil {
+		return fmt.Errorf("db error delete followers of artist %d: %w", fromId, err)
+	}
+	if _, err = tx.Exec(addArtistAliasStmt, toId, fromId); err != nil {
+		return fmt.Errorf("db error add alias of artist %d: %w", fromId, err)
+	}
+	if _, err = tx.Exec(moveArtistAliasesStmt, toId, fromId); err != nil {
+		return fmt.Errorf("db error move aliases of artist %d: %w", fromId, err)
+	}
+
+	res, err := tx.Exec(deleteArtistStmt, fromId)
+	if err != nil {
+		return fmt.Errorf("db error delete artist %d: %w", fromId, err)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return ErrArtistNotFound
+	}
+
+	return tx.Commit()
+}
+
 func (a *ArtistSqliteRepo) CloseArtistRepo() {
 	a.DB.Close()
 }
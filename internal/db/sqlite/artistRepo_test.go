@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
 )
 
 func TestArtistRepo(t *testing.T) {
@@ -123,6 +126,10 @@ func prepareTestDb(t testing.TB) *sqlx.DB {
 		log.Fatalf("error while connecting to test db: %s", err)
 	}
 
+	if err = CheckFTS5(db); err != nil {
+		log.Fatal(err)
+	}
+
 	// turn off migrations log, but catch error
 	log.SetOutput(io.Discard)
 	goose.SetDialect("sqlite3")
@@ -144,3 +151,119 @@ func removeTestDB(t testing.TB, db *sqlx.DB) {
 		log.Fatalf("error while deleting test database file: %s", err)
 	}
 }
+
+func TestFindArtistByName(t *testing.T) {
+	t.Run("case insensitive", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewArtistSqliteRepo(db)
+		id, _ := repo.AddArtist("Lil Yachty")
+
+		got, err := repo.FindArtistByName("lil yachty")
+		assert.NoError(t, err)
+		assert.Equal(t, id, got.Id)
+		assert.Equal(t, "Lil Yachty", got.Name)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		got, err := NewArtistSqliteRepo(db).FindArtistByName("Drake")
+		assert.ErrorIs(t, err, ErrArtistNotFound)
+		assert.Nil(t, got)
+	})
+}
+
+func TestMergeArtists(t *testing.T) {
+	t.Run("moves releases and followers", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		artistRepo := NewArtistSqliteRepo(db)
+		releaseRepo := NewReleaseSqliteRepo(db)
+		followsRepo := NewFollowsSqliteRepo(db)
+		usersRepo := NewUserSqliteRepo(db)
+
+		duplicateId, _ := artistRepo.AddArtist("Jay Z")
+		artistId, _ := artistRepo.AddArtist("JAY-Z")
+		releaseRepo.AddRelease(models.Release{
+			Id:      1,
+			Title:   "4:44",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2017, time.June, 30),
+		}, duplicateId)
+
+		usersRepo.AddUser(models.User{Id: 1, Username: "first"})
+		usersRepo.AddUser(models.User{Id: 2, Username: "second"})
+		followsRepo.FollowArtist(1, duplicateId)
+		followsRepo.FollowArtist(2, duplicateId)
+		followsRepo.FollowArtist(2, artistId)
+
+		err := artistRepo.MergeArtists(duplicateId, artistId)
+		assert.NoError(t, err)
+
+		release, err := releaseRepo.GetReleaseById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, artistId, release.Artist.Id)
+
+		followers, err := followsRepo.GetArtistFollowers(artistId)
+		assert.NoError(t, err)
+		assert.Len(t, followers, 2)
+
+		_, err = artistRepo.GetArtistById(duplicateId)
+		assert.Error(t, err)
+	})
+
+	t.Run("refresh keeps merged artist", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewSqliteRepository(db)
+		release := models.Release{
+			Id:      1,
+			Artist:  models.Artist{Name: "Jay Z"},
+			Title:   "4:44",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2017, time.June, 30),
+		}
+		repo.CreateMultiArtistsAndReleases([]models.Release{release})
+		duplicate, _ := repo.GetArtistByName("Jay Z")
+		artistId, _ := repo.AddArtist("JAY-Z")
+		assert.NoError(t, repo.MergeArtists(duplicate.Id, artistId))
+
+		// HipHopDX по-прежнему пишет "Jay Z"
+		single := release
+		single.Id, single.Title, single.Type = 2, "Glory", models.Single
+		inserted, err := repo.CreateMultiArtistsAndReleases([]models.Release{release, single})
+		assert.NoError(t, err)
+		assert.Len(t, inserted, 1)
+
+		got, err := repo.GetReleaseById(2)
+		assert.NoError(t, err)
+		assert.Equal(t, artistId, got.Artist.Id)
+
+		artist, err := repo.FindArtistByName("jay z")
+		assert.NoError(t, err)
+		assert.Equal(t, "JAY-Z", artist.Name)
+
+		// второе объединение переносит и старые псевдонимы
+		otherId, _ := repo.AddArtist("Shawn Carter")
+		assert.NoError(t, repo.MergeArtists(artistId, otherId))
+		artist, err = repo.GetArtistByName("Jay Z")
+		assert.NoError(t, err)
+		assert.Equal(t, otherId, artist.Id)
+	})
+
+	t.Run("missing artist", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewArtistSqliteRepo(db)
+		artistId, _ := repo.AddArtist("JAY-Z")
+
+		assert.ErrorIs(t, repo.MergeArtists(404, artistId), ErrArtistNotFound)
+		assert.Error(t, repo.MergeArtists(artistId, artistId))
+	})
+}
@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.ChatsRepositoryInterface = (*ChatsSqliteRepo)(nil)
+
+var ErrChatNotFound = errors.New("chat not found")
+
+const (
+	// при повторном добавлении бота в чат настройки рассылки сохраняются
+	addChatStmt = `
+    INSERT INTO chats (id, title, type, language)
+    VALUES (?, ?, ?, ?)
+    ON CONFLICT (id) DO UPDATE SET title = excluded.title, type = excluded.type;
+    `
+
+	getChatQuery = `
+    SELECT id, title, type, timezone, digest_time, last_digest_date, language
+    FROM chats
+    WHERE id = ?;
+    `
+
+	deleteChatSubscriptionsStmt = `DELETE FROM chat_subscriptions WHERE chat_id = ?;`
+
+	deleteChatStmt = `DELETE FROM chats WHERE id = ?;`
+
+	setChatTimezoneStmt = `UPDATE chats SET timezone = ? WHERE id = ?;`
+
+	setChatDigestTimeStmt = `UPDATE chats SET digest_time = ? WHERE id = ?;`
+
+	setChatLastDigestDateStmt = `UPDATE chats SET last_digest_date = ? WHERE id = ?;`
+
+	subscribeChatStmt = `INSERT OR IGNORE INTO chat_subscriptions (chat_id, kind) VALUES (?, ?);`
+
+	unsubscribeChatStmt = `DELETE FROM chat_subscriptions WHERE chat_id = ? AND kind = ?;`
+
+	getChatSubscriptionsQuery = `SELECT kind FROM chat_subscriptions WHERE chat_id = ?;`
+
+	getSubscribedChatsQuery = `
+    SELECT c.id, c.title, c.type, c.timezone, c.digest_time, c.last_digest_date, c.language
+    FROM chat_subscriptions AS s
+    JOIN chats AS c ON s.chat_id = c.id
+    WHERE s.kind = ?;`
+)
+
+type ChatSqlite struct {
+	Id             int64  `db:"id"`
+	Title          string `db:"title"`
+	Type           string `db:"type"`
+	Timezone       string `db:"timezone"`
+	DigestTime     string `db:"digest_time"`
+	LastDigestDate string `db:"last_digest_date"`
+	Language       string `db:"language"`
+}
+
+func (c ChatSqlite) ToModel() *models.Chat {
+	return &models.Chat{
+		Id:             c.Id,
+		Title:          c.Title,
+		Type:           c.Type,
+		Timezone:       c.Timezone,
+		DigestTime:     c.DigestTime,
+		LastDigestDate: c.LastDigestDate,
+		Language:       c.Language,
+	}
+}
+
+type ChatsSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewChatsSqliteRepo(db *sqlx.DB) *ChatsSqliteRepo {
+	return &ChatsSqliteRepo{db}
+}
+
+func (c *ChatsSqliteRepo) AddChat(chat models.Chat) error {
+	_, err := c.DB.Exec(addChatStmt, chat.Id, chat.Title, chat.Type, chat.Language)
+	if err != nil {
+		return fmt.Errorf("db error add chat: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) GetChat(chatId int64) (*models.Chat, error) {
+	var chats []ChatSqlite
+	err := c.DB.Select(&chats, getChatQuery, chatId)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting chat: %w", err)
+	}
+
+	if len(chats) == 0 {
+		return nil, ErrChatNotFound
+	}
+
+	return chats[0].ToModel(), nil
+}
+
+// DeleteChat удаляет чат вместе с его рассылками, внешние ключи в sqlite выключены,
+// см. SqliteRepository.
+func (c *ChatsSqliteRepo) DeleteChat(chatId int64) error {
+	tx, err := c.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error while starting delete chat transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(deleteChatSubscriptionsStmt, chatId); err != nil {
+		return fmt.Errorf("db error delete chat subscriptions: %w", err)
+	}
+	if _, err = tx.Exec(deleteChatStmt, chatId); err != nil {
+		return fmt.Errorf("db error delete chat: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (c *ChatsSqliteRepo) SetChatTimezone(chatId int64, timezone string) error {
+	_, err := c.DB.Exec(setChatTimezoneStmt, timezone, chatId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set chat timezone: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) SetChatDigestTime(chatId int64, digestTime string) error {
+	_, err := c.DB.Exec(setChatDigestTimeStmt, digestTime, chatId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set chat digest time: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) SetChatLastDigestDate(chatId int64, date string) error {
+	_, err := c.DB.Exec(setChatLastDigestDateStmt, date, chatId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set chat last digest date: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) SubscribeChat(chatId int64, kind models.SubscriptionKind) error {
+	_, err := c.DB.Exec(subscribeChatStmt, chatId, kind)
+	if err != nil {
+		return fmt.Errorf("db error subscribe chat to %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) UnsubscribeChat(chatId int64, kind models.SubscriptionKind) error {
+	_, err := c.DB.Exec(unsubscribeChatStmt, chatId, kind)
+	if err != nil {
+		return fmt.Errorf("db error unsubscribe chat from %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func (c *ChatsSqliteRepo) GetChatSubscriptions(chatId int64) ([]models.SubscriptionKind, error) {
+	var kinds []models.SubscriptionKind
+	err := c.DB.Select(&kinds, getChatSubscriptionsQuery, chatId)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting chat subscriptions: %w", err)
+	}
+
+	return kinds, nil
+}
+
+func (c *ChatsSqliteRepo) GetSubscribedChats(kind models.SubscriptionKind) ([]*models.Chat, error) {
+	var chats []ChatSqlite
+	err := c.DB.Select(&chats, getSubscribedChatsQuery, kind)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting chats subscribed to %s: %w", kind, err)
+	}
+
+	if len(chats) == 0 {
+		return nil, ErrChatNotFound
+	}
+
+	chatsResult := make([]*models.Chat, 0, len(chats))
+	for _, chat := range chats {
+		chatsResult = append(chatsResult, chat.ToModel())
+	}
+
+	return chatsResult, nil
+}
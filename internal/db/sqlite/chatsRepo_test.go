@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+)
+
+func TestAddChat(t *testing.T) {
+	t.Run("re-adding chat keeps settings", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewChatsSqliteRepo(db)
+		chat := models.Chat{Id: -100, Title: "Hip-Hop team", Type: "supergroup", Language: "en"}
+		assert.NoError(t, repo.AddChat(chat))
+		assert.NoError(t, repo.SetChatTimezone(chat.Id, "Europe/Moscow"))
+		assert.NoError(t, repo.SetChatDigestTime(chat.Id, "10:00"))
+
+		chat.Title = "Hip-Hop crew"
+		assert.NoError(t, repo.AddChat(chat))
+
+		chatFromDb, err := repo.GetChat(chat.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hip-Hop crew", chatFromDb.Title)
+		assert.Equal(t, "Europe/Moscow", chatFromDb.Timezone)
+		assert.Equal(t, "10:00", chatFromDb.DigestTime)
+		assert.Equal(t, "en", chatFromDb.Language)
+	})
+
+	t.Run("chat not found", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		_, err := NewChatsSqliteRepo(db).GetChat(-100)
+		assert.ErrorIs(t, err, ErrChatNotFound)
+	})
+}
+
+func TestChatSubscriptions(t *testing.T) {
+	t.Run("only subscribed chats of kind", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewChatsSqliteRepo(db)
+		repo.AddChat(models.Chat{Id: -100, Type: "group"})
+		repo.AddChat(models.Chat{Id: -200, Type: "channel"})
+		assert.NoError(t, repo.SubscribeChat(-100, models.HistorySubscription))
+		assert.NoError(t, repo.SubscribeChat(-100, models.HistorySubscription))
+		assert.NoError(t, repo.SubscribeChat(-200, models.TodayReleasesSubscription))
+
+		kinds, err := repo.GetChatSubscriptions(-100)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SubscriptionKind{models.HistorySubscription}, kinds)
+
+		chats, err := repo.GetSubscribedChats(models.HistorySubscription)
+		assert.NoError(t, err)
+		assert.Len(t, chats, 1)
+		assert.Equal(t, int64(-100), chats[0].Id)
+
+		assert.NoError(t, repo.UnsubscribeChat(-100, models.HistorySubscription))
+		_, err = repo.GetSubscribedChats(models.HistorySubscription)
+		assert.ErrorIs(t, err, ErrChatNotFound)
+	})
+
+	t.Run("delete chat removes subscriptions", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewChatsSqliteRepo(db)
+		repo.AddChat(models.Chat{Id: -100, Type: "group"})
+		repo.SubscribeChat(-100, models.WeeklyDigestSubscription)
+
+		assert.NoError(t, repo.DeleteChat(-100))
+
+		_, err := repo.GetChat(-100)
+		assert.ErrorIs(t, err, ErrChatNotFound)
+		kinds, err := repo.GetChatSubscriptions(-100)
+		assert.NoError(t, err)
+		assert.Empty(t, kinds)
+	})
+}
@@ -4,6 +4,10 @@ import "errors"
 
 var (
 	ErrArtistAlreadyExists  = errors.New("artist with this name already exists")
+	ErrArtistNotFound       = errors.New("artist not found")
 	ErrReleaseAlreadyExists = errors.New("release with that id already exists")
 	ErrReleasesNotFound     = errors.New("releases not found")
+	ErrReleaseDeleted       = errors.New("release was deleted by admin")
+	ErrAlreadyFollowing     = errors.New("user already follows this artist")
+	ErrNoFTS5               = errors.New("sqlite is built without FTS5, build with -tags sqlite_fts5")
 )
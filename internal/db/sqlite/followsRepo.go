@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.FollowsRepositoryInterface = (*FollowsSqliteRepo)(nil)
+
+const (
+	followArtistStmt = `INSERT INTO follows (user_id, artist_id) VALUES (?, ?);`
+
+	unfollowArtistStmt = `DELETE FROM follows WHERE user_id = ? AND artist_id = ?;`
+
+	getFollowedArtistsQuery = `
+    SELECT a.artist_id, a.name
+    FROM follows AS f
+    JOIN artists AS a ON f.artist_id = a.artist_id
+    WHERE f.user_id = ?
+    ORDER BY a.name;`
+
+	getArtistFollowersQuery = `
+    SELECT u.id, u.username,
+    u.timezone, u.digest_time, u.last_digest_date, u.release_type_filter,
+    u.language
+    FROM follows AS f
+    JOIN users AS u ON f.user_id = u.id
+    WHERE f.artist_id = ? AND u.is_active = 1;`
+)
+
+type FollowsSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewFollowsSqliteRepo(db *sqlx.DB) *FollowsSqliteRepo {
+	return &FollowsSqliteRepo{db}
+}
+
+func (f *FollowsSqliteRepo) FollowArtist(userId int64, artistId int) error {
+	_, err := f.DB.Exec(followArtistStmt, userId, artistId)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed:") {
+			return ErrAlreadyFollowing
+		}
+		return fmt.Errorf("db error follow artist: %w", err)
+	}
+
+	return nil
+}
+
+func (f *FollowsSqliteRepo) UnfollowArtist(userId int64, artistId int) error {
+	_, err := f.DB.Exec(unfollowArtistStmt, userId, artistId)
+	if err != nil {
+		return fmt.Errorf("db error unfollow artist: %w", err)
+	}
+
+	return nil
+}
+
+func (f *FollowsSqliteRepo) GetFollowedArtists(userId int64) ([]*db.ArtistDB, error) {
+	var artists []ArtistSqlite
+	err := f.DB.Select(&artists, getFollowedArtistsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting followed artists: %w", err)
+	}
+
+	if len(artists) == 0 {
+		return nil, ErrArtistNotFound
+	}
+
+	artistsResult := make([]*db.ArtistDB, 0, len(artists))
+	for _, artist := range artists {
+		artistsResult = append(artistsResult, &db.ArtistDB{
+			Id:   artist.Id,
+			Name: artist.Name,
+		})
+	}
+
+	return artistsResult, nil
+}
+
+func (f *FollowsSqliteRepo) GetArtistFollowers(artistId int) ([]*models.User, error) {
+	var users []UserSqlite
+	err := f.DB.Select(&users, getArtistFollowersQuery, artistId)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting artist followers: %w", err)
+	}
+
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	usersResult := make([]*models.User, 0, len(users))
+	for _, user := range users {
+		usersResult = append(usersResult, user.ToModel())
+	}
+
+	return usersResult, nil
+}
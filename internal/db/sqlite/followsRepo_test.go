@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+func TestFollowArtist(t *testing.T) {
+	t.Run("success case", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		user := models.User{Id: 1, Username: "forsigg"}
+		NewUserSqliteRepo(db).AddUser(user)
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+
+		repo := NewFollowsSqliteRepo(db)
+		err := repo.FollowArtist(user.Id, artistId)
+		assert.NoError(t, err)
+
+		followers, err := repo.GetArtistFollowers(artistId)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.User{&user}, followers)
+	})
+
+	t.Run("follow twice", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+
+		repo := NewFollowsSqliteRepo(db)
+		repo.FollowArtist(1, artistId)
+		err := repo.FollowArtist(1, artistId)
+		assert.ErrorIs(t, err, ErrAlreadyFollowing)
+	})
+}
+
+func TestUnfollowArtist(t *testing.T) {
+	t.Run("success case", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+
+		repo := NewFollowsSqliteRepo(db)
+		repo.FollowArtist(1, artistId)
+		err := repo.UnfollowArtist(1, artistId)
+		assert.NoError(t, err)
+
+		followers, err := repo.GetArtistFollowers(artistId)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.Nil(t, followers)
+	})
+}
+
+func TestGetFollowedArtists(t *testing.T) {
+	t.Run("sorted by name", func(t *testing.T) {
+		dbConn := prepareTestDb(t)
+		defer removeTestDB(t, dbConn)
+
+		NewUserSqliteRepo(dbConn).AddUser(models.User{Id: 1, Username: "forsigg"})
+		artistRepo := NewArtistSqliteRepo(dbConn)
+		drakeId, _ := artistRepo.AddArtist("Drake")
+		savageId, _ := artistRepo.AddArtist("21 Savage")
+
+		repo := NewFollowsSqliteRepo(dbConn)
+		repo.FollowArtist(1, drakeId)
+		repo.FollowArtist(1, savageId)
+
+		got, err := repo.GetFollowedArtists(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*db.ArtistDB{
+			{Id: savageId, Name: "21 Savage"},
+			{Id: drakeId, Name: "Drake"},
+		}, got)
+	})
+
+	t.Run("nothing followed", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		got, err := NewFollowsSqliteRepo(db).GetFollowedArtists(1)
+		assert.ErrorIs(t, err, ErrArtistNotFound)
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.PublicationsRepositoryInterface = (*PublicationsSqliteRepo)(nil)
+
+const (
+	addPublicationStmt = `
+    INSERT OR IGNORE INTO publications (channel_id, kind, date, message_id)
+    VALUES (?, ?, ?, ?);
+    `
+
+	isPublishedQuery = `
+    SELECT COUNT(*) FROM publications
+    WHERE channel_id = ? AND kind = ? AND date = ?;
+    `
+)
+
+type PublicationsSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewPublicationsSqliteRepo(db *sqlx.DB) *PublicationsSqliteRepo {
+	return &PublicationsSqliteRepo{db}
+}
+
+func (p *PublicationsSqliteRepo) AddPublication(publication models.Publication) error {
+	_, err := p.DB.Exec(
+		addPublicationStmt,
+		publication.ChannelId,
+		publication.Kind,
+		publication.Date,
+		publication.MessageId,
+	)
+	if err != nil {
+		return fmt.Errorf("db error add publication: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PublicationsSqliteRepo) IsPublished(
+	channelId int64,
+	kind models.SubscriptionKind,
+	date string,
+) (bool, error) {
+	var count int
+	err := p.DB.Get(&count, isPublishedQuery, channelId, kind, date)
+	if err != nil {
+		return false, fmt.Errorf("error while checking publication: %w", err)
+	}
+
+	return count > 0, nil
+}
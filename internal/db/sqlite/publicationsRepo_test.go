@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+)
+
+func TestPublications(t *testing.T) {
+	t.Run("publication is recorded once per channel, kind and date", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewPublicationsSqliteRepo(db)
+		publication := models.Publication{
+			ChannelId: -100,
+			Kind:      models.TodayReleasesSubscription,
+			Date:      "2024-08-12",
+			MessageId: 42,
+		}
+
+		published, err := repo.IsPublished(-100, models.TodayReleasesSubscription, "2024-08-12")
+		assert.NoError(t, err)
+		assert.False(t, published)
+
+		assert.NoError(t, repo.AddPublication(publication))
+		assert.NoError(t, repo.AddPublication(publication))
+
+		published, err = repo.IsPublished(-100, models.TodayReleasesSubscription, "2024-08-12")
+		assert.NoError(t, err)
+		assert.True(t, published)
+
+		published, _ = repo.IsPublished(-100, models.HistorySubscription, "2024-08-12")
+		assert.False(t, published)
+		published, _ = repo.IsPublished(-200, models.TodayReleasesSubscription, "2024-08-12")
+		assert.False(t, published)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jmoiron/sqlx"
 
@@ -14,10 +15,20 @@ import (
 )
 
 const (
+	// удаленные администратором релизы не добавляются при следующем обновлении
 	createReleaseStmt = `INSERT INTO releases
                         (release_id, artist_id, release_type, title, out_year, out_month, out_day, cover_url)
-                        VALUES
-                        (?, ?, ?, ?, ?, ?, ?, ?);`
+                        SELECT ?, ?, ?, ?, ?, ?, ?, ?
+                        WHERE NOT EXISTS (SELECT 1 FROM deleted_releases WHERE release_id = ?);`
+
+	// id релизов HipHopDX положительные, добавленным вручную выдаем отрицательные
+	createManualReleaseStmt = `INSERT INTO releases
+                        (release_id, artist_id, release_type, title, out_year, out_month, out_day, cover_url)
+                        SELECT MIN(
+                            0,
+                            IFNULL((SELECT MIN(release_id) FROM releases), 0),
+                            IFNULL((SELECT MIN(release_id) FROM deleted_releases), 0)
+                        ) - 1, ?, ?, ?, ?, ?, ?, ?;`
 
 	getReleaseByIdQuery = `
     SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
@@ -29,8 +40,8 @@ const (
     SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
     FROM releases AS r
     JOIN artists AS a ON r.artist_id = "artist.artist_id" 
-    WHERE r.out_month = ? AND r.out_year = ?
-    ORDER BY r.out_year, r.out_month, r.out_day
+    WHERE r.out_month = ? AND r.out_year = ? AND (? = 0 OR r.release_type = ?)
+    ORDER BY r.out_year, r.out_month, r.out_day, a.name, r.release_id
     LIMIT ? OFFSET ?;`
 
 	getReleasesWithoutCoverQuery = `
@@ -44,8 +55,8 @@ const (
     SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
     FROM releases AS r
     JOIN artists AS a ON r.artist_id = "artist.artist_id" 
-    WHERE r.out_year = ?
-    ORDER BY r.out_year, r.out_month, r.out_day
+    WHERE r.out_year = ? AND (? = 0 OR r.release_type = ?)
+    ORDER BY r.out_year, r.out_month, r.out_day, a.name, r.release_id
     LIMIT ? OFFSET ?;`
 
 	getReleasesByNameQuery = `
@@ -58,14 +69,62 @@ const (
     SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
     FROM releases AS r
     JOIN artists AS a ON r.artist_id = "artist.artist_id" 
-    WHERE r.out_year = ? AND r.out_month = ? AND r.out_day = ?
+    WHERE r.out_year = ? AND r.out_month = ? AND r.out_day = ? AND (? = 0 OR r.release_type = ?)
+    ORDER BY a.name, r.release_id
+    LIMIT ? OFFSET ?;`
+
+	searchReleasesQuery = `
+    SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
+    FROM releases_search AS s
+    JOIN releases AS r ON r.release_id = s.rowid
+    JOIN artists AS a ON r.artist_id = a.artist_id
+    WHERE releases_search MATCH ?
+    ORDER BY r.out_year DESC, r.out_month DESC, r.out_day DESC, a.name, r.release_id
     LIMIT ? OFFSET ?;`
 
+	getReleasesByPeriodQuery = `
+    SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
+    FROM releases AS r
+    JOIN artists AS a ON r.artist_id = a.artist_id
+    WHERE r.out_year * 10000 + r.out_month * 100 + r.out_day BETWEEN ? AND ?
+    ORDER BY r.out_year, r.out_month, r.out_day, a.name, r.release_id
+    LIMIT ? OFFSET ?;`
+
+	getReleaseYearsQuery = `SELECT DISTINCT out_year FROM releases ORDER BY out_year;`
+
 	updateReleaseCoverStmt = `
     UPDATE releases
     SET cover_url = ?
     WHERE release_id = ?;
     `
+
+	updateReleaseTitleStmt = `UPDATE releases SET title = ? WHERE release_id = ?;`
+
+	updateReleaseArtistStmt = `UPDATE releases SET artist_id = ? WHERE release_id = ?;`
+
+	updateReleaseTypeStmt = `UPDATE releases SET release_type = ? WHERE release_id = ?;`
+
+	// дата, исправленная администратором, больше не синхронизируется с HipHopDX
+	updateReleaseDateStmt = `
+    UPDATE releases
+    SET out_year = ?, out_month = ?, out_day = ?, date_overridden = 1
+    WHERE release_id = ?;
+    `
+
+	syncReleaseDateStmt = `
+    UPDATE releases
+    SET out_year = ?, out_month = ?, out_day = ?
+    WHERE release_id = ? AND date_overridden = 0
+    AND out_year * 10000 + out_month * 100 + out_day != ?;
+    `
+
+	deleteReleaseStmt = `DELETE FROM releases WHERE release_id = ?;`
+
+	addDeletedReleaseStmt = `INSERT OR IGNORE INTO deleted_releases (release_id) VALUES (?);`
+
+	deleteReleaseRemindersStmt = `DELETE FROM reminders WHERE release_id = ?;`
+
+	deleteSavedReleaseStmt = `DELETE FROM saved_releases WHERE release_id = ?;`
 )
 
 type ReleaseSqlite struct {
@@ -102,6 +161,7 @@ func (r *ReleaseSqliteRepo) AddRelease(release models.Release, artId int) (int,
 		release.OutDate.Month(),
 		release.OutDate.Day(),
 		release.CoverUrl.Value,
+		release.Id,
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: releases.release_id") {
@@ -109,6 +169,9 @@ func (r *ReleaseSqliteRepo) AddRelease(release models.Release, artId int) (int,
 		}
 		return 0, fmt.Errorf("db error add release: %s", err)
 	}
+	if inserted, err := res.RowsAffected(); err == nil && inserted == 0 {
+		return 0, ErrReleaseDeleted
+	}
 	releaseId, err := res.LastInsertId()
 	if err != nil {
 		return 0, fmt.Errorf("error while inserting release: %s", err)
@@ -117,6 +180,29 @@ func (r *ReleaseSqliteRepo) AddRelease(release models.Release, artId int) (int,
 	return int(releaseId), nil
 }
 
+// AddManualRelease добавляет релиз, которого нет на HipHopDX, id релиза выдается базой.
+func (r *ReleaseSqliteRepo) AddManualRelease(release models.Release, artId int) (int, error) {
+	res, err := r.DB.Exec(
+		createManualReleaseStmt,
+		artId,
+		release.Type,
+		release.Title,
+		release.OutDate.Year(),
+		release.OutDate.Month(),
+		release.OutDate.Day(),
+		release.CoverUrl.Value,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("db error add manual release: %w", err)
+	}
+	releaseId, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error while inserting manual release: %w", err)
+	}
+
+	return int(releaseId), nil
+}
+
 func (r *ReleaseSqliteRepo) GetReleaseById(id int) (*db.ReleaseDB, error) {
 	var releases []ReleaseSqlite
 	err := r.DB.Select(&releases, getReleaseByIdQuery, id)
@@ -127,7 +213,7 @@ func (r *ReleaseSqliteRepo) GetReleaseById(id int) (*db.ReleaseDB, error) {
 	if len(releases) > 1 {
 		return nil, errors.New("getting release by id returns few values, but expected one")
 	} else if len(releases) == 0 {
-		return nil, fmt.Errorf("release with id %d not found", id)
+		return nil, fmt.Errorf("release with id %d: %w", id, ErrReleasesNotFound)
 	}
 
 	return &db.ReleaseDB{
@@ -175,17 +261,113 @@ func (r *ReleaseSqliteRepo) UpdateReleaseCoverUrl(releaseId int, coverUrl string
 	return nil
 }
 
+func (r *ReleaseSqliteRepo) UpdateReleaseTitle(releaseId int, title string) error {
+	return r.updateRelease(releaseId, "title", updateReleaseTitleStmt, title, releaseId)
+}
+
+func (r *ReleaseSqliteRepo) UpdateReleaseArtist(releaseId int, artId int) error {
+	return r.updateRelease(releaseId, "artist", updateReleaseArtistStmt, artId, releaseId)
+}
+
+func (r *ReleaseSqliteRepo) UpdateReleaseType(releaseId int, releaseType models.ReleaseType) error {
+	return r.updateRelease(releaseId, "type", updateReleaseTypeStmt, releaseType, releaseId)
+}
+
+func (r *ReleaseSqliteRepo) UpdateReleaseDate(releaseId int, date time.Time) error {
+	return r.updateRelease(
+		releaseId,
+		"date",
+		updateReleaseDateStmt,
+		date.Year(), date.Month(), date.Day(), releaseId,
+	)
+}
+
+// SyncReleaseDate ставит релизу дату с HipHopDX, если она изменилась и администратор
+// не исправлял дату вручную. Возвращает, была ли дата перенесена.
+func (r *ReleaseSqliteRepo) SyncReleaseDate(releaseId int, date time.Time) (bool, error) {
+	res, err := r.DB.Exec(
+		syncReleaseDateStmt,
+		date.Year(), date.Month(), date.Day(), releaseId, dateToInt(date),
+	)
+	if err != nil {
+		return false, fmt.Errorf("error while syncing date of release(id %d): %w", releaseId, err)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error while syncing date of release(id %d): %w", releaseId, err)
+	}
+
+	return updated > 0, nil
+}
+
+// updateRelease возвращает ErrReleasesNotFound, если релиза с таким id нет.
+func (r *ReleaseSqliteRepo) updateRelease(releaseId int, field, stmt string, args ...any) error {
+	res, err := r.DB.Exec(stmt, args...)
+	if err != nil {
+		return fmt.Errorf("error while updating %s in release(id %d): %w", field, releaseId, err)
+	}
+	if updated, err := res.RowsAffected(); err == nil && updated == 0 {
+		return ErrReleasesNotFound
+	}
+
+	return nil
+}
+
+// DeleteRelease удаляет релиз вместе с напоминаниями о нем и из списков пользователей
+// и запоминает его id, чтобы обновление не добавило релиз снова.
+func (r *ReleaseSqliteRepo) DeleteRelease(releaseId int) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error while starting delete release transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(deleteReleaseStmt, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error delete release(id %d): %w", releaseId, err)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return ErrReleasesNotFound
+	}
+	if _, err = tx.Exec(addDeletedReleaseStmt, releaseId); err != nil {
+		return fmt.Errorf("db error mark release(id %d) deleted: %w", releaseId, err)
+	}
+	if _, err = tx.Exec(deleteReleaseRemindersStmt, releaseId); err != nil {
+		return fmt.Errorf("db error delete reminders of release(id %d): %w", releaseId, err)
+	}
+	if _, err = tx.Exec(deleteSavedReleaseStmt, releaseId); err != nil {
+		return fmt.Errorf("db error delete saved release(id %d): %w", releaseId, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetReleasesByMonth при releaseType = models.AnyType возвращает релизы всех типов.
 func (r *ReleaseSqliteRepo) GetReleasesByMonth(
 	month time.Month,
-	year, limit, offset int,
+	year int,
+	releaseType models.ReleaseType,
+	limit, offset int,
 ) ([]*db.ReleaseDB, error) {
 	var releasesFromDB []ReleaseSqlite
 
 	var err error
 	if month == 0 {
-		err = r.DB.Select(&releasesFromDB, getReleasesByYearQuery, year, limit, offset)
+		err = r.DB.Select(
+			&releasesFromDB,
+			getReleasesByYearQuery,
+			year,
+			releaseType, releaseType,
+			limit, offset,
+		)
 	} else {
-		err = r.DB.Select(&releasesFromDB, getReleasesByMonthQuery, month, year, limit, offset)
+		err = r.DB.Select(
+			&releasesFromDB,
+			getReleasesByMonthQuery,
+			month, year,
+			releaseType, releaseType,
+			limit, offset,
+		)
 	}
 
 	if err != nil {
@@ -215,7 +397,7 @@ func (r *ReleaseSqliteRepo) GetReleasesByMonth(
 }
 
 func (r *ReleaseSqliteRepo) GetReleasesByYear(year, limit, offset int) ([]*db.ReleaseDB, error) {
-	releases, err := r.GetReleasesByMonth(0, year, limit, offset)
+	releases, err := r.GetReleasesByMonth(0, year, models.AnyType, limit, offset)
 	if err != nil {
 		if errors.Is(err, ErrReleasesNotFound) {
 			return nil, err
@@ -259,12 +441,19 @@ func (r *ReleaseSqliteRepo) GetReleasesWithoutCover() ([]*db.ReleaseDB, error) {
 func (r *ReleaseSqliteRepo) GetReleasesByDay(
 	year int,
 	month time.Month,
-	day,
+	day int,
+	releaseType models.ReleaseType,
 	limit,
 	offset int,
 ) ([]*db.ReleaseDB, error) {
 	var releasesFromDB []ReleaseSqlite
-	err := r.DB.Select(&releasesFromDB, getReleasesByDayQuery, year, month, day, limit, offset)
+	err := r.DB.Select(
+		&releasesFromDB,
+		getReleasesByDayQuery,
+		year, month, day,
+		releaseType, releaseType,
+		limit, offset,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error while getting releases by day: %w", err)
 	}
@@ -289,3 +478,114 @@ func (r *ReleaseSqliteRepo) GetReleasesByDay(
 
 	return releasesResult, nil
 }
+
+// GetReleasesByPeriod возвращает релизы с from по to включительно,
+// учитываются только даты, время игнорируется.
+func (r *ReleaseSqliteRepo) GetReleasesByPeriod(
+	from, to time.Time,
+	limit, offset int,
+) ([]*db.ReleaseDB, error) {
+	var releasesFromDB []ReleaseSqlite
+	err := r.DB.Select(
+		&releasesFromDB,
+		getReleasesByPeriodQuery,
+		dateToInt(from),
+		dateToInt(to),
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting releases by period: %w", err)
+	}
+
+	if len(releasesFromDB) == 0 {
+		return nil, ErrReleasesNotFound
+	}
+
+	releasesResult := make([]*db.ReleaseDB, 0, len(releasesFromDB))
+	for _, rel := range releasesFromDB {
+		releasesResult = append(releasesResult, &db.ReleaseDB{
+			Id:       rel.Id,
+			Artist:   db.ArtistDB(rel.Artist),
+			Title:    rel.Title,
+			Type:     rel.Type,
+			OutYear:  rel.OutYear,
+			OutMonth: rel.OutMonth,
+			OutDay:   rel.OutDay,
+			CoverUrl: rel.CoverUrl.String,
+		})
+	}
+
+	return releasesResult, nil
+}
+
+// dateToInt переводит дату в число вида 20240112, в таком же виде
+// дата релиза собирается из колонок out_year, out_month и out_day.
+func dateToInt(date time.Time) int {
+	return date.Year()*10000 + int(date.Month())*100 + date.Day()
+}
+
+func (r *ReleaseSqliteRepo) GetReleaseYears() ([]int, error) {
+	var years []int
+	err := r.DB.Select(&years, getReleaseYearsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting release years: %w", err)
+	}
+
+	if len(years) == 0 {
+		return nil, ErrReleasesNotFound
+	}
+
+	return years, nil
+}
+
+// SearchReleases ищет релизы по полнотекстовому индексу releases_search.
+// Каждое слово запроса ищется как префикс в имени исполнителя или названии,
+// свежие релизы идут первыми.
+func (r *ReleaseSqliteRepo) SearchReleases(query string, limit, offset int) ([]*db.ReleaseDB, error) {
+	matchQuery := buildMatchQuery(query)
+	if matchQuery == "" {
+		return nil, ErrReleasesNotFound
+	}
+
+	var releasesFromDB []ReleaseSqlite
+	err := r.DB.Select(&releasesFromDB, searchReleasesQuery, matchQuery, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error while searching releases: %w", err)
+	}
+
+	if len(releasesFromDB) == 0 {
+		return nil, ErrReleasesNotFound
+	}
+
+	releasesResult := make([]*db.ReleaseDB, 0, len(releasesFromDB))
+	for _, rel := range releasesFromDB {
+		releasesResult = append(releasesResult, &db.ReleaseDB{
+			Id:       rel.Id,
+			Artist:   db.ArtistDB(rel.Artist),
+			Title:    rel.Title,
+			Type:     rel.Type,
+			OutYear:  rel.OutYear,
+			OutMonth: rel.OutMonth,
+			OutDay:   rel.OutDay,
+			CoverUrl: rel.CoverUrl.String,
+		})
+	}
+
+	return releasesResult, nil
+}
+
+// buildMatchQuery превращает пользовательский ввод в выражение для MATCH:
+// оставляет только буквы и цифры, чтобы ввод не мог содержать операторы FTS,
+// и добавляет к каждому слову * для поиска по префиксу.
+func buildMatchQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + "*"
+	}
+
+	return strings.Join(words, " ")
+}
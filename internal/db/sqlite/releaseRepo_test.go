@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -196,12 +197,48 @@ func TestGetReleasesByMonth(t *testing.T) {
 			releaseRepo.AddRelease(release, artistId)
 		}
 
-		got, err := releaseRepo.GetReleasesByMonth(time.January, 2024, 2, 0)
+		got, err := releaseRepo.GetReleasesByMonth(time.January, 2024, models.AnyType, 2, 0)
 		assert.NoError(t, err, "error didn't expected")
 		assert.Equal(t, 2, len(got))
 		assert.Equal(t, got[0].Title, "American Dream")
 		assert.Equal(t, got[1].Title, "Another Release")
 	})
+
+	t.Run("filter by release type", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+		releases := []models.Release{
+			{
+				Id:      1,
+				Artist:  models.Artist{Name: "21 Savage"},
+				Title:   "American Dream",
+				Type:    models.Album,
+				OutDate: types.NewCustomDate(2024, time.January, 12),
+			},
+			{
+				Id:      2,
+				Artist:  models.Artist{Name: "Drake"},
+				Title:   "Single Release",
+				Type:    models.Single,
+				OutDate: types.NewCustomDate(2024, time.January, 20),
+			},
+		}
+		artistRepo := NewArtistSqliteRepo(db)
+		releaseRepo := NewReleaseSqliteRepo(db)
+
+		for _, release := range releases {
+			artistId, _ := artistRepo.AddArtist(release.Artist.Name)
+			releaseRepo.AddRelease(release, artistId)
+		}
+
+		got, err := releaseRepo.GetReleasesByMonth(time.January, 2024, models.Single, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, "Single Release", got[0].Title)
+
+		_, err = releaseRepo.GetReleasesByMonth(time.March, 2024, models.Album, 10, 0)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+	})
 }
 
 func TestGetReleasesByYear(t *testing.T) {
@@ -360,10 +397,362 @@ func TestGetReleasesByDay(t *testing.T) {
 		artistId, _ := artistRepo.AddArtist(release.Artist.Name)
 		releaseRepo.AddRelease(release, artistId)
 
-		got, err := releaseRepo.GetReleasesByDay(2024, time.January, 12, 10, 0)
+		got, err := releaseRepo.GetReleasesByDay(2024, time.January, 12, models.AnyType, 10, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, len(got), 1)
 		assert.Equal(t, "American Dream", got[0].Title)
+
+		got, err = releaseRepo.GetReleasesByDay(2024, time.January, 12, models.Single, 10, 0)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+		assert.Nil(t, got)
+	})
+
+	t.Run("same day releases keep page order", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewSqliteRepository(db)
+		var releases []models.Release
+		for i, name := range []string{"Future", "Drake", "Future", "Asake", "Drake"} {
+			releases = append(releases, models.Release{
+				Id:      i + 1,
+				Artist:  models.Artist{Name: name},
+				Title:   fmt.Sprintf("Release %d", i+1),
+				Type:    models.Album,
+				OutDate: types.NewCustomDate(2024, time.January, 12),
+			})
+		}
+		repo.CreateMultiArtistsAndReleases(releases)
+
+		expected := []int{4, 2, 5, 1, 3}
+		var byDay, byMonth []int
+		for offset := 0; offset < len(releases); offset += 2 {
+			page, err := repo.GetReleasesByDay(2024, time.January, 12, models.AnyType, 2, offset)
+			assert.NoError(t, err)
+			byDay = append(byDay, releaseIds(page)...)
+
+			page, err = repo.GetReleasesByMonth(time.January, 2024, models.AnyType, 2, offset)
+			assert.NoError(t, err)
+			byMonth = append(byMonth, releaseIds(page)...)
+		}
+		assert.Equal(t, expected, byDay)
+		assert.Equal(t, expected, byMonth)
+	})
+}
+
+func TestSearchReleases(t *testing.T) {
+	releases := []models.Release{
+		{
+			Id:      1,
+			Artist:  models.Artist{Name: "Kendrick Lamar"},
+			Title:   "GNX",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2024, time.November, 22),
+		},
+		{
+			Id:      2,
+			Artist:  models.Artist{Name: "Kendrick Lamar"},
+			Title:   "Not Like Us",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2024, time.May, 4),
+		},
+		{
+			Id:      3,
+			Artist:  models.Artist{Name: "Drake"},
+			Title:   "100% Certified",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2024, time.June, 1),
+		},
+	}
+
+	t.Run("search by artist prefix, newest first", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		got, err := NewReleaseSqliteRepo(db).SearchReleases("kend", 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(got))
+		assert.Equal(t, "GNX", got[0].Title)
+		assert.Equal(t, "Not Like Us", got[1].Title)
+	})
+
+	t.Run("search by artist and title", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		got, err := NewReleaseSqliteRepo(db).SearchReleases("lamar - not", 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, 2, got[0].Id)
+	})
+
+	t.Run("special symbols are ignored", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		got, err := NewReleaseSqliteRepo(db).SearchReleases("\"100%\" (certif*", 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got))
+
+		got, err = NewReleaseSqliteRepo(db).SearchReleases("%_", 10, 0)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+		assert.Nil(t, got)
+	})
+
+	t.Run("index follows artist rename", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+		db.MustExec(`UPDATE artists SET name = 'Aubrey Graham' WHERE name = 'Drake';`)
+
+		got, err := NewReleaseSqliteRepo(db).SearchReleases("aubrey", 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, "Aubrey Graham", got[0].Artist.Name)
+	})
+}
+
+func TestBuildMatchQuery(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"single word", "Kendrick", "kendrick*"},
+		{"few words with punctuation", "21 Savage - American Dream", "21* savage* american* dream*"},
+		{"fts operators", `"drake" OR NEAR(*)`, "drake* or* near*"},
+		{"cyrillic", "Баста", "баста*"},
+		{"only symbols", "%_-", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, buildMatchQuery(tc.query))
+		})
+	}
+}
+
+func TestGetReleaseYears(t *testing.T) {
+	t.Run("distinct sorted years", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases([]models.Release{
+			{
+				Id:      1,
+				Artist:  models.Artist{Name: "21 Savage"},
+				Title:   "American Dream",
+				Type:    models.Album,
+				OutDate: types.NewCustomDate(2024, time.January, 12),
+			},
+			{
+				Id:      2,
+				Artist:  models.Artist{Name: "Drake"},
+				Title:   "For All The Dogs",
+				Type:    models.Album,
+				OutDate: types.NewCustomDate(2023, time.October, 6),
+			},
+			{
+				Id:      3,
+				Artist:  models.Artist{Name: "Drake"},
+				Title:   "Family Matters",
+				Type:    models.Single,
+				OutDate: types.NewCustomDate(2024, time.May, 3),
+			},
+		})
+
+		got, err := NewReleaseSqliteRepo(db).GetReleaseYears()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2023, 2024}, got)
+	})
+
+	t.Run("empty database", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		got, err := NewReleaseSqliteRepo(db).GetReleaseYears()
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+		assert.Nil(t, got)
+	})
+}
+
+func TestGetReleasesByPeriod(t *testing.T) {
+	releases := []models.Release{
+		{
+			Id:      1,
+			Artist:  models.Artist{Name: "Drake"},
+			Title:   "Before",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2024, time.December, 31),
+		},
+		{
+			Id:      2,
+			Artist:  models.Artist{Name: "Eminem"},
+			Title:   "First Day",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2025, time.January, 1),
+		},
+		{
+			Id:      3,
+			Artist:  models.Artist{Name: "21 Savage"},
+			Title:   "Same Day",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2025, time.January, 1),
+		},
+		{
+			Id:      4,
+			Artist:  models.Artist{Name: "Drake"},
+			Title:   "Last Day",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2025, time.January, 7),
+		},
+		{
+			Id:      5,
+			Artist:  models.Artist{Name: "Drake"},
+			Title:   "After",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2025, time.January, 8),
+		},
+	}
+
+	t.Run("bounds are inclusive and sorted by date and artist", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		from := time.Date(2025, time.January, 1, 15, 30, 0, 0, time.UTC)
+		got, err := NewReleaseSqliteRepo(db).GetReleasesByPeriod(from, from.AddDate(0, 0, 6), 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(got))
+		assert.Equal(t, "Same Day", got[0].Title)
+		assert.Equal(t, "First Day", got[1].Title)
+		assert.Equal(t, "Last Day", got[2].Title)
+	})
+
+	t.Run("nothing in period", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		from := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+		got, err := NewReleaseSqliteRepo(db).GetReleasesByPeriod(from, from.AddDate(0, 0, 7), 10, 0)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+		assert.Nil(t, got)
+	})
+}
+
+func TestEditRelease(t *testing.T) {
+	release := models.Release{
+		Id:      1,
+		Artist:  models.Artist{Name: "21 Savage"},
+		Title:   "Amercan Dream",
+		Type:    models.Single,
+		OutDate: types.NewCustomDate(2024, time.January, 1),
+	}
+
+	t.Run("update fields", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		releaseRepo := NewReleaseSqliteRepo(db)
+		artistRepo := NewArtistSqliteRepo(db)
+		artistId, _ := artistRepo.AddArtist("21 Savage")
+		newArtistId, _ := artistRepo.AddArtist("Metro Boomin")
+		releaseRepo.AddRelease(release, artistId)
+
+		assert.NoError(t, releaseRepo.UpdateReleaseTitle(release.Id, "American Dream"))
+		assert.NoError(t, releaseRepo.UpdateReleaseArtist(release.Id, newArtistId))
+		assert.NoError(t, releaseRepo.UpdateReleaseType(release.Id, models.Album))
+		assert.NoError(t, releaseRepo.UpdateReleaseDate(
+			release.Id,
+			time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC),
+		))
+
+		got, err := releaseRepo.GetReleaseById(release.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "American Dream", got.Title)
+		assert.Equal(t, "Metro Boomin", got.Artist.Name)
+		assert.Equal(t, int(models.Album), got.Type)
+		assert.Equal(t, []int{2024, 1, 12}, []int{got.OutYear, got.OutMonth, got.OutDay})
+
+		found, err := releaseRepo.SearchReleases("metro american", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 1)
+	})
+
+	t.Run("update missing release", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		err := NewReleaseSqliteRepo(db).UpdateReleaseTitle(404, "American Dream")
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+	})
+
+	t.Run("deleted release is not added again", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		releaseRepo := NewReleaseSqliteRepo(db)
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+		releaseRepo.AddRelease(release, artistId)
+
+		assert.NoError(t, releaseRepo.DeleteRelease(release.Id))
+		_, err := releaseRepo.GetReleaseById(release.Id)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+
+		_, err = releaseRepo.AddRelease(release, artistId)
+		assert.ErrorIs(t, err, ErrReleaseDeleted)
+		assert.ErrorIs(t, releaseRepo.DeleteRelease(release.Id), ErrReleasesNotFound)
+	})
+
+	t.Run("admin date survives refresh", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		sqliteRepo := NewSqliteRepository(db)
+		sqliteRepo.CreateMultiArtistsAndReleases([]models.Release{release})
+		fixedDate := time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)
+		assert.NoError(t, sqliteRepo.UpdateReleaseDate(release.Id, fixedDate))
+
+		moved := release
+		moved.OutDate = types.NewCustomDate(2024, time.February, 2)
+		_, err := sqliteRepo.CreateMultiArtistsAndReleases([]models.Release{moved})
+		assert.NoError(t, err)
+
+		got, err := sqliteRepo.GetReleaseById(release.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2024, 1, 12}, []int{got.OutYear, got.OutMonth, got.OutDay})
+	})
+
+	t.Run("manual releases get negative ids", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		releaseRepo := NewReleaseSqliteRepo(db)
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+		releaseRepo.AddRelease(release, artistId)
+
+		firstId, err := releaseRepo.AddManualRelease(release, artistId)
+		assert.NoError(t, err)
+		assert.Equal(t, -1, firstId)
+
+		releaseRepo.DeleteRelease(firstId)
+		secondId, err := releaseRepo.AddManualRelease(release, artistId)
+		assert.NoError(t, err)
+		assert.Equal(t, -2, secondId)
+
+		got, err := releaseRepo.GetReleaseById(secondId)
+		assert.NoError(t, err)
+		assert.Equal(t, release.Title, got.Title)
 	})
 }
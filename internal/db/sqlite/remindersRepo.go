@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.RemindersRepositoryInterface = (*RemindersSqliteRepo)(nil)
+
+const (
+	addReminderStmt = `INSERT OR IGNORE INTO reminders (user_id, release_id) VALUES (?, ?);`
+
+	deleteReminderStmt = `DELETE FROM reminders WHERE user_id = ? AND release_id = ?;`
+
+	setReminderAttemptDateStmt = `
+    UPDATE reminders SET last_attempt_date = ? WHERE user_id = ? AND release_id = ?;`
+
+	hasReminderQuery = `SELECT COUNT(*) FROM reminders WHERE user_id = ? AND release_id = ?;`
+
+	getDueRemindersQuery = `
+    SELECT u.id, u.username,
+    u.timezone, u.digest_time, u.last_digest_date, u.release_type_filter,
+    u.language, rem.release_id, rem.last_attempt_date
+    FROM reminders AS rem
+    JOIN users AS u ON rem.user_id = u.id
+    JOIN releases AS r ON rem.release_id = r.release_id
+    WHERE r.out_year * 10000 + r.out_month * 100 + r.out_day <= ? AND u.is_active = 1
+    ORDER BY u.id;`
+)
+
+type ReminderSqlite struct {
+	UserSqlite
+	ReleaseId       int    `db:"release_id"`
+	LastAttemptDate string `db:"last_attempt_date"`
+}
+
+type RemindersSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewRemindersSqliteRepo(db *sqlx.DB) *RemindersSqliteRepo {
+	return &RemindersSqliteRepo{db}
+}
+
+// AddReminder не считает ошибкой повторное напоминание о том же релизе.
+func (r *RemindersSqliteRepo) AddReminder(userId int64, releaseId int) error {
+	_, err := r.DB.Exec(addReminderStmt, userId, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error add reminder: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RemindersSqliteRepo) DeleteReminder(userId int64, releaseId int) error {
+	_, err := r.DB.Exec(deleteReminderStmt, userId, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error delete reminder: %w", err)
+	}
+
+	return nil
+}
+
+// SetReminderAttemptDate запоминает день неудачной отправки, чтобы не повторять ее в тот же день.
+func (r *RemindersSqliteRepo) SetReminderAttemptDate(userId int64, releaseId int, date string) error {
+	_, err := r.DB.Exec(setReminderAttemptDateStmt, date, userId, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error set reminder attempt date: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RemindersSqliteRepo) HasReminder(userId int64, releaseId int) (bool, error) {
+	var count int
+	err := r.DB.Get(&count, hasReminderQuery, userId, releaseId)
+	if err != nil {
+		return false, fmt.Errorf("error while checking reminder: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// GetDueReminders возвращает напоминания активных пользователей о релизах,
+// которые выходят не позже until.
+func (r *RemindersSqliteRepo) GetDueReminders(until time.Time) ([]models.Reminder, error) {
+	var reminders []ReminderSqlite
+	err := r.DB.Select(&reminders, getDueRemindersQuery, dateToInt(until))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting due reminders: %w", err)
+	}
+
+	result := make([]models.Reminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		result = append(result, models.Reminder{
+			User:            reminder.UserSqlite.ToModel(),
+			ReleaseId:       reminder.ReleaseId,
+			LastAttemptDate: reminder.LastAttemptDate,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestReminders(t *testing.T) {
+	release := models.Release{
+		Id:      1,
+		Artist:  models.Artist{Name: "Kendrick Lamar"},
+		Title:   "GNX",
+		Type:    models.Album,
+		OutDate: types.NewCustomDate(2024, time.November, 22),
+	}
+	user := models.User{Id: 1, Username: "forsigg"}
+
+	t.Run("add, check and delete reminder", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(user)
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases([]models.Release{release})
+
+		repo := NewRemindersSqliteRepo(db)
+		assert.NoError(t, repo.AddReminder(user.Id, release.Id))
+		assert.NoError(t, repo.AddReminder(user.Id, release.Id))
+
+		has, err := repo.HasReminder(user.Id, release.Id)
+		assert.NoError(t, err)
+		assert.True(t, has)
+
+		assert.NoError(t, repo.DeleteReminder(user.Id, release.Id))
+		has, err = repo.HasReminder(user.Id, release.Id)
+		assert.NoError(t, err)
+		assert.False(t, has)
+	})
+
+	t.Run("due reminders follow release date", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(user)
+		sqliteRepo := NewSqliteRepository(db)
+		sqliteRepo.CreateMultiArtistsAndReleases([]models.Release{release})
+
+		repo := NewRemindersSqliteRepo(db)
+		repo.AddReminder(user.Id, release.Id)
+
+		got, err := repo.GetDueReminders(time.Date(2024, time.November, 21, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = repo.GetDueReminders(time.Date(2024, time.November, 22, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Reminder{{User: &user, ReleaseId: release.Id}}, got)
+
+		// HipHopDX перенес релиз на неделю
+		moved := release
+		moved.OutDate = types.NewCustomDate(2024, time.November, 29)
+		inserted, err := sqliteRepo.CreateMultiArtistsAndReleases([]models.Release{moved})
+		assert.NoError(t, err)
+		assert.Empty(t, inserted)
+
+		got, err = repo.GetDueReminders(time.Date(2024, time.November, 22, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = repo.GetDueReminders(time.Date(2024, time.November, 29, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("failed attempt date", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(user)
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases([]models.Release{release})
+
+		repo := NewRemindersSqliteRepo(db)
+		repo.AddReminder(user.Id, release.Id)
+		assert.NoError(t, repo.SetReminderAttemptDate(user.Id, release.Id, "2024-11-22"))
+
+		got, err := repo.GetDueReminders(time.Date(2024, time.November, 22, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, "2024-11-22", got[0].LastAttemptDate)
+		}
+	})
+
+	t.Run("inactive users and deleted releases are skipped", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		usersRepo := NewUserSqliteRepo(db)
+		usersRepo.AddUser(user)
+		usersRepo.AddUser(models.User{Id: 2, Username: "blocked"})
+		usersRepo.SetUserActive(2, false)
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases([]models.Release{release})
+
+		repo := NewRemindersSqliteRepo(db)
+		repo.AddReminder(user.Id, release.Id)
+		repo.AddReminder(2, release.Id)
+		until := time.Date(2024, time.November, 22, 0, 0, 0, 0, time.UTC)
+
+		got, err := repo.GetDueReminders(until)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, user.Id, got[0].User.Id)
+
+		assert.NoError(t, NewReleaseSqliteRepo(db).DeleteRelease(release.Id))
+		has, err := repo.HasReminder(user.Id, release.Id)
+		assert.NoError(t, err)
+		assert.False(t, has)
+	})
+}
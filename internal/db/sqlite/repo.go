@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -12,10 +13,21 @@ import (
 	"hip-hop-geek/internal/models"
 )
 
+// SqliteRepository собирает все репозитории над одной базой. Внешние ключи в sqlite
+// выключены: ключ releases ссылается на несуществующий artists.id, и с _foreign_keys=on
+// не вставился бы ни один релиз. Поэтому ON DELETE CASCADE в миграциях не работает,
+// а зависимые строки удаляют DeleteRelease, DeleteChat и MergeArtists.
 type SqliteRepository struct {
 	db.ArtistsRepositoryInterface
 	db.ReleaseRepositoryInterface
 	db.UsersRepositoryInterface
+	db.FollowsRepositoryInterface
+	db.SubscriptionsRepositoryInterface
+	db.ChatsRepositoryInterface
+	db.PublicationsRepositoryInterface
+	db.RemindersRepositoryInterface
+	db.SavedReleasesRepositoryInterface
+	db.StatsRepositoryInterface
 }
 
 func NewSqliteRepository(db *sqlx.DB) *SqliteRepository {
@@ -23,9 +35,32 @@ func NewSqliteRepository(db *sqlx.DB) *SqliteRepository {
 		NewArtistSqliteRepo(db),
 		NewReleaseSqliteRepo(db),
 		NewUserSqliteRepo(db),
+		NewFollowsSqliteRepo(db),
+		NewSubscriptionsSqliteRepo(db),
+		NewChatsSqliteRepo(db),
+		NewPublicationsSqliteRepo(db),
+		NewRemindersSqliteRepo(db),
+		NewSavedReleasesSqliteRepo(db),
+		NewStatsSqliteRepo(db),
 	}
 }
 
+const checkFTS5Query = `SELECT sqlite_compileoption_used('ENABLE_FTS5')`
+
+// CheckFTS5 проверяет, что go-sqlite3 собран с FTS5. Без тега sqlite_fts5 миграция
+// поиска падает с непонятной ошибкой "no such module: fts5", поэтому проверяем заранее.
+func CheckFTS5(db *sqlx.DB) error {
+	var enabled bool
+	if err := db.Get(&enabled, checkFTS5Query); err != nil {
+		return fmt.Errorf("error while checking sqlite compile options: %w", err)
+	}
+	if !enabled {
+		return ErrNoFTS5
+	}
+
+	return nil
+}
+
 func (s *SqliteRepository) Close() {
 	s.CloseArtistRepo()
 	s.CloseReleaseRepo()
@@ -59,7 +94,13 @@ func (s *SqliteRepository) CreateReleaseWithArtist(release models.Release) (int,
 	return releaseId, nil
 }
 
-func (s *SqliteRepository) CreateMultiArtistsAndReleases(releases []models.Release) error {
+// CreateMultiArtistsAndReleases добавляет релизы и недостающих исполнителей в базу,
+// у уже существующих релизов обновляется только дата выхода, если HipHopDX ее перенес,
+// а администратор не исправлял ее через /editrelease.
+// Возвращает только реально добавленные релизы.
+func (s *SqliteRepository) CreateMultiArtistsAndReleases(
+	releases []models.Release,
+) ([]models.Release, error) {
 	log.Println("start inserting releases into db...")
 	artistIdToReleaseMap := make(map[int][]models.Release, 0)
 	for _, release := range releases {
@@ -72,29 +113,42 @@ func (s *SqliteRepository) CreateMultiArtistsAndReleases(releases []models.Relea
 				log.Println(err.Error())
 				artistId, err := s.AddArtist(release.Artist.Name)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				artistIdToReleaseMap[artistId] = append(artistIdToReleaseMap[artistId], release)
 			} else {
-				return err
+				return nil, err
 			}
 		} else {
 			artistIdToReleaseMap[artist.Id] = append(artistIdToReleaseMap[artist.Id], release)
 		}
 	}
 
+	newReleases := make([]models.Release, 0)
 	for artistId, releasesArr := range artistIdToReleaseMap {
 		for _, release := range releasesArr {
 			_, err := s.AddRelease(release, artistId)
 			if err != nil {
 				if errors.Is(err, ErrReleaseAlreadyExists) {
+					// напоминания берут дату из релиза и переносятся вместе с ним
+					moved, err := s.SyncReleaseDate(release.Id, release.OutDate.Time)
+					if err != nil {
+						return nil, err
+					}
+					if moved {
+						log.Printf("release %d moved to %s", release.Id, release.OutDate.Format(time.DateOnly))
+					}
+					continue
+				}
+				if errors.Is(err, ErrReleaseDeleted) {
 					continue
 				}
 				log.Printf("inserted release %s - %s", release.Artist.Name, release.Title)
-				return err
+				return nil, err
 			}
+			newReleases = append(newReleases, release)
 		}
 	}
 
-	return nil
+	return newReleases, nil
 }
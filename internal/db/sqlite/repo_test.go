@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestCheckFTS5(t *testing.T) {
+	db := prepareTestDb(t)
+	defer removeTestDB(t, db)
+
+	assert.NoError(t, CheckFTS5(db))
+}
+
+func TestNoOrphanRows(t *testing.T) {
+	db := prepareTestDb(t)
+	defer removeTestDB(t, db)
+
+	repo := NewSqliteRepository(db)
+	repo.AddUser(models.User{Id: 1, Username: "forsigg"})
+	repo.CreateMultiArtistsAndReleases([]models.Release{
+		{
+			Id:      1,
+			Artist:  models.Artist{Name: "Jay Z"},
+			Title:   "4:44",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2017, time.June, 30),
+		},
+		{
+			Id:      2,
+			Artist:  models.Artist{Name: "JAY-Z"},
+			Title:   "The Blueprint",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2001, time.September, 11),
+		},
+	})
+	duplicate, _ := repo.GetArtistByName("Jay Z")
+	artist, _ := repo.GetArtistByName("JAY-Z")
+
+	repo.FollowArtist(1, duplicate.Id)
+	repo.SaveRelease(1, 1)
+	repo.AddReminder(1, 1)
+	repo.AddChat(models.Chat{Id: -100, Title: "Hip-Hop team", Type: "supergroup"})
+	repo.SubscribeChat(-100, models.TodayReleasesSubscription)
+
+	assert.NoError(t, repo.MergeArtists(duplicate.Id, artist.Id))
+	assert.NoError(t, repo.DeleteRelease(1))
+	assert.NoError(t, repo.DeleteChat(-100))
+
+	// внешние ключи выключены, поэтому проверяем, что строки удалены явно
+	for _, table := range []string{"follows", "saved_releases", "reminders", "chat_subscriptions"} {
+		var orphans []struct {
+			Table  string `db:"table"`
+			RowId  int    `db:"rowid"`
+			Parent string `db:"parent"`
+			FkId   int    `db:"fkid"`
+		}
+		assert.NoError(t, db.Select(&orphans, "PRAGMA foreign_key_check("+table+");"))
+		assert.Empty(t, orphans, table)
+	}
+}
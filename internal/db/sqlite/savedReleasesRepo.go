@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+)
+
+var _ db.SavedReleasesRepositoryInterface = (*SavedReleasesSqliteRepo)(nil)
+
+const (
+	saveReleaseStmt = `INSERT OR IGNORE INTO saved_releases (user_id, release_id) VALUES (?, ?);`
+
+	unsaveReleaseStmt = `DELETE FROM saved_releases WHERE user_id = ? AND release_id = ?;`
+
+	isReleaseSavedQuery = `SELECT COUNT(*) FROM saved_releases WHERE user_id = ? AND release_id = ?;`
+
+	// rowid различает релизы, сохраненные в одну секунду
+	getSavedReleasesQuery = `
+    SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
+    FROM saved_releases AS s
+    JOIN releases AS r ON s.release_id = r.release_id
+    JOIN artists AS a ON r.artist_id = a.artist_id
+    WHERE s.user_id = ?
+    ORDER BY s.saved_at DESC, s.rowid DESC
+    LIMIT ? OFFSET ?;`
+
+	getSavedReleasesByDateQuery = `
+    SELECT r.release_id, a.artist_id AS "artist.artist_id", a.name AS "artist.name", r.title, r.out_year, r.out_month, r.out_day, r.cover_url, r.release_type
+    FROM saved_releases AS s
+    JOIN releases AS r ON s.release_id = r.release_id
+    JOIN artists AS a ON r.artist_id = a.artist_id
+    WHERE s.user_id = ?
+    ORDER BY r.out_year, r.out_month, r.out_day, a.name, r.release_id
+    LIMIT ? OFFSET ?;`
+)
+
+type SavedReleasesSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewSavedReleasesSqliteRepo(db *sqlx.DB) *SavedReleasesSqliteRepo {
+	return &SavedReleasesSqliteRepo{db}
+}
+
+// SaveRelease не считает ошибкой повторное сохранение релиза.
+func (s *SavedReleasesSqliteRepo) SaveRelease(userId int64, releaseId int) error {
+	_, err := s.DB.Exec(saveReleaseStmt, userId, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error save release: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SavedReleasesSqliteRepo) UnsaveRelease(userId int64, releaseId int) error {
+	_, err := s.DB.Exec(unsaveReleaseStmt, userId, releaseId)
+	if err != nil {
+		return fmt.Errorf("db error unsave release: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SavedReleasesSqliteRepo) IsReleaseSaved(userId int64, releaseId int) (bool, error) {
+	var count int
+	err := s.DB.Get(&count, isReleaseSavedQuery, userId, releaseId)
+	if err != nil {
+		return false, fmt.Errorf("error while checking saved release: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// GetSavedReleases возвращает сохраненные релизы пользователя: последние сохраненные
+// первыми или, если byDate, по дате выхода.
+func (s *SavedReleasesSqliteRepo) GetSavedReleases(
+	userId int64,
+	byDate bool,
+	limit, offset int,
+) ([]*db.ReleaseDB, error) {
+	query := getSavedReleasesQuery
+	if byDate {
+		query = getSavedReleasesByDateQuery
+	}
+
+	var releasesFromDB []ReleaseSqlite
+	err := s.DB.Select(&releasesFromDB, query, userId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting saved releases: %w", err)
+	}
+
+	if len(releasesFromDB) == 0 {
+		return nil, ErrReleasesNotFound
+	}
+
+	releasesResult := make([]*db.ReleaseDB, 0, len(releasesFromDB))
+	for _, rel := range releasesFromDB {
+		releasesResult = append(releasesResult, &db.ReleaseDB{
+			Id:       rel.Id,
+			Artist:   db.ArtistDB(rel.Artist),
+			Title:    rel.Title,
+			Type:     rel.Type,
+			OutYear:  rel.OutYear,
+			OutMonth: rel.OutMonth,
+			OutDay:   rel.OutDay,
+			CoverUrl: rel.CoverUrl.String,
+		})
+	}
+
+	return releasesResult, nil
+}
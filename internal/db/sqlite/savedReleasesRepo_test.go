@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestSavedReleases(t *testing.T) {
+	releases := []models.Release{
+		{
+			Id:      1,
+			Artist:  models.Artist{Name: "Kendrick Lamar"},
+			Title:   "GNX",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2024, time.November, 22),
+		},
+		{
+			Id:      2,
+			Artist:  models.Artist{Name: "21 Savage"},
+			Title:   "American Dream",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2024, time.January, 12),
+		},
+		{
+			Id:      3,
+			Artist:  models.Artist{Name: "Drake"},
+			Title:   "For All The Dogs",
+			Type:    models.Album,
+			OutDate: types.NewCustomDate(2023, time.October, 6),
+		},
+	}
+
+	t.Run("save, check and unsave", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		repo := NewSavedReleasesSqliteRepo(db)
+		assert.NoError(t, repo.SaveRelease(1, 1))
+		assert.NoError(t, repo.SaveRelease(1, 1))
+
+		saved, err := repo.IsReleaseSaved(1, 1)
+		assert.NoError(t, err)
+		assert.True(t, saved)
+
+		assert.NoError(t, repo.UnsaveRelease(1, 1))
+		saved, err = repo.IsReleaseSaved(1, 1)
+		assert.NoError(t, err)
+		assert.False(t, saved)
+
+		got, err := repo.GetSavedReleases(1, false, 10, 0)
+		assert.ErrorIs(t, err, ErrReleasesNotFound)
+		assert.Nil(t, got)
+	})
+
+	t.Run("sort by saving and by release date", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 2, Username: "other"})
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		repo := NewSavedReleasesSqliteRepo(db)
+		repo.SaveRelease(1, 2)
+		repo.SaveRelease(1, 1)
+		repo.SaveRelease(1, 3)
+		repo.SaveRelease(2, 1)
+
+		got, err := repo.GetSavedReleases(1, false, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 1, 2}, releaseIds(got))
+
+		got, err = repo.GetSavedReleases(1, true, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 2, 1}, releaseIds(got))
+
+		got, err = repo.GetSavedReleases(1, true, 2, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, releaseIds(got))
+	})
+
+	t.Run("deleted release leaves the list", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+		NewSqliteRepository(db).CreateMultiArtistsAndReleases(releases)
+
+		repo := NewSavedReleasesSqliteRepo(db)
+		repo.SaveRelease(1, 1)
+		repo.SaveRelease(1, 2)
+		assert.NoError(t, NewReleaseSqliteRepo(db).DeleteRelease(1))
+
+		saved, err := repo.IsReleaseSaved(1, 1)
+		assert.NoError(t, err)
+		assert.False(t, saved)
+
+		got, err := repo.GetSavedReleases(1, false, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2}, releaseIds(got))
+	})
+}
+
+func releaseIds(releases []*db.ReleaseDB) []int {
+	ids := make([]int, 0, len(releases))
+	for _, release := range releases {
+		ids = append(ids, release.Id)
+	}
+	return ids
+}
@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.StatsRepositoryInterface = (*StatsSqliteRepo)(nil)
+
+const (
+	addUpdaterRunStmt = `
+    INSERT INTO updater_runs (started_at, finished_at, new_releases)
+    VALUES (?, ?, ?);
+    `
+
+	addDigestRunStmt = `
+    INSERT INTO digest_runs (started_at, finished_at, recipients, failed)
+    VALUES (?, ?, ?, ?);
+    `
+
+	countUsersQuery = `SELECT COUNT(*) FROM users;`
+
+	countInactiveUsersQuery = `SELECT COUNT(*) FROM users WHERE is_active = 0;`
+
+	countChatsQuery = `SELECT COUNT(*) FROM chats;`
+
+	countArtistsQuery = `SELECT COUNT(*) FROM artists;`
+
+	countReleasesWithoutCoverQuery = `
+    SELECT COUNT(*) FROM releases
+    WHERE cover_url IS NULL OR cover_url = "";
+    `
+
+	subscribersByKindQuery = `
+    SELECT s.kind, COUNT(*) AS count
+    FROM subscriptions AS s
+    JOIN users AS u ON s.user_id = u.id
+    WHERE u.is_active = 1
+    GROUP BY s.kind;
+    `
+
+	releasesByYearQuery = `
+    SELECT out_year,
+    SUM(release_type = 1) AS albums,
+    SUM(release_type = 2) AS singles
+    FROM releases
+    GROUP BY out_year
+    ORDER BY out_year;
+    `
+
+	getLastUpdaterRunQuery = `
+    SELECT started_at, finished_at, new_releases
+    FROM updater_runs
+    ORDER BY id DESC
+    LIMIT 1;
+    `
+
+	getLastDigestRunQuery = `
+    SELECT started_at, finished_at, recipients, failed
+    FROM digest_runs
+    ORDER BY id DESC
+    LIMIT 1;
+    `
+)
+
+type UpdaterRunSqlite struct {
+	StartedAt   time.Time `db:"started_at"`
+	FinishedAt  time.Time `db:"finished_at"`
+	NewReleases int       `db:"new_releases"`
+}
+
+type DigestRunSqlite struct {
+	StartedAt  time.Time `db:"started_at"`
+	FinishedAt time.Time `db:"finished_at"`
+	Recipients int       `db:"recipients"`
+	Failed     int       `db:"failed"`
+}
+
+type SubscribersCountSqlite struct {
+	Kind  models.SubscriptionKind `db:"kind"`
+	Count int                     `db:"count"`
+}
+
+type YearReleasesSqlite struct {
+	Year    int `db:"out_year"`
+	Albums  int `db:"albums"`
+	Singles int `db:"singles"`
+}
+
+type StatsSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewStatsSqliteRepo(db *sqlx.DB) *StatsSqliteRepo {
+	return &StatsSqliteRepo{db}
+}
+
+func (s *StatsSqliteRepo) AddUpdaterRun(run models.UpdaterRun) error {
+	_, err := s.DB.Exec(addUpdaterRunStmt, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.NewReleases)
+	if err != nil {
+		return fmt.Errorf("db error add updater run: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StatsSqliteRepo) AddDigestRun(run models.DigestRun) error {
+	_, err := s.DB.Exec(
+		addDigestRunStmt,
+		run.StartedAt.UTC(),
+		run.FinishedAt.UTC(),
+		run.Recipients,
+		run.Failed,
+	)
+	if err != nil {
+		return fmt.Errorf("db error add digest run: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StatsSqliteRepo) GetStats() (*models.Stats, error) {
+	stats := &models.Stats{Subscribers: make(map[models.SubscriptionKind]int)}
+
+	counters := []struct {
+		query string
+		dest  *int
+	}{
+		{countUsersQuery, &stats.Users},
+		{countInactiveUsersQuery, &stats.InactiveUsers},
+		{countChatsQuery, &stats.Chats},
+		{countArtistsQuery, &stats.Artists},
+		{countReleasesWithoutCoverQuery, &stats.ReleasesWithoutCover},
+	}
+	for _, counter := range counters {
+		if err := s.DB.Get(counter.dest, counter.query); err != nil {
+			return nil, fmt.Errorf("error while counting stats: %w", err)
+		}
+	}
+
+	var subscribers []SubscribersCountSqlite
+	if err := s.DB.Select(&subscribers, subscribersByKindQuery); err != nil {
+		return nil, fmt.Errorf("error while counting subscribers: %w", err)
+	}
+	for _, count := range subscribers {
+		stats.Subscribers[count.Kind] = count.Count
+	}
+
+	var years []YearReleasesSqlite
+	if err := s.DB.Select(&years, releasesByYearQuery); err != nil {
+		return nil, fmt.Errorf("error while counting releases by year: %w", err)
+	}
+	for _, year := range years {
+		stats.ReleasesByYear = append(stats.ReleasesByYear, models.YearReleasesStats(year))
+	}
+
+	var updaterRuns []UpdaterRunSqlite
+	if err := s.DB.Select(&updaterRuns, getLastUpdaterRunQuery); err != nil {
+		return nil, fmt.Errorf("error while getting last updater run: %w", err)
+	}
+	if len(updaterRuns) != 0 {
+		run := models.UpdaterRun(updaterRuns[0])
+		stats.LastUpdaterRun = &run
+	}
+
+	var digestRuns []DigestRunSqlite
+	if err := s.DB.Select(&digestRuns, getLastDigestRunQuery); err != nil {
+		return nil, fmt.Errorf("error while getting last digest run: %w", err)
+	}
+	if len(digestRuns) != 0 {
+		run := models.DigestRun(digestRuns[0])
+		stats.LastDigestRun = &run
+	}
+
+	return stats, nil
+}
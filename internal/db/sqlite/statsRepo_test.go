@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+	"hip-hop-geek/internal/types"
+)
+
+func TestGetStats(t *testing.T) {
+	t.Run("empty database", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		stats, err := NewStatsSqliteRepo(db).GetStats()
+		assert.NoError(t, err)
+		assert.Zero(t, stats.Users)
+		assert.Empty(t, stats.ReleasesByYear)
+		assert.Nil(t, stats.LastUpdaterRun)
+		assert.Nil(t, stats.LastDigestRun)
+	})
+
+	t.Run("counts and last runs", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		usersRepo := NewUserSqliteRepo(db)
+		usersRepo.AddUser(models.User{Id: 1, Username: "forsigg"})
+		usersRepo.AddUser(models.User{Id: 2, Username: "kanye"})
+		usersRepo.AddUser(models.User{Id: 3, Username: "blocked"})
+		usersRepo.SetUserActive(3, false)
+		subscriptionsRepo := NewSubscriptionsSqliteRepo(db)
+		subscriptionsRepo.Subscribe(1, models.HistorySubscription)
+		subscriptionsRepo.Subscribe(2, models.HistorySubscription)
+		subscriptionsRepo.Subscribe(2, models.WeeklyDigestSubscription)
+		subscriptionsRepo.Subscribe(3, models.WeeklyDigestSubscription)
+
+		artId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+		releaseRepo := NewReleaseSqliteRepo(db)
+		releaseRepo.AddRelease(models.Release{
+			Id:       1,
+			Title:    "American Dream",
+			Type:     models.Album,
+			OutDate:  types.NewCustomDate(2024, time.January, 12),
+			CoverUrl: models.CoverUrl{Value: "https://covers/1.jpg", IsValid: true},
+		}, artId)
+		releaseRepo.AddRelease(models.Release{
+			Id:      2,
+			Title:   "Redrum",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2024, time.January, 5),
+		}, artId)
+		releaseRepo.AddRelease(models.Release{
+			Id:      3,
+			Title:   "Bank Account",
+			Type:    models.Single,
+			OutDate: types.NewCustomDate(2023, time.June, 1),
+		}, artId)
+
+		repo := NewStatsSqliteRepo(db)
+		started := time.Date(2024, time.August, 12, 8, 0, 0, 0, time.UTC)
+		assert.NoError(t, repo.AddUpdaterRun(models.UpdaterRun{
+			StartedAt: started.Add(-8 * time.Hour), FinishedAt: started.Add(-7 * time.Hour),
+		}))
+		assert.NoError(t, repo.AddUpdaterRun(models.UpdaterRun{
+			StartedAt: started, FinishedAt: started.Add(time.Minute), NewReleases: 3,
+		}))
+		assert.NoError(t, repo.AddDigestRun(models.DigestRun{
+			StartedAt: started, FinishedAt: started.Add(time.Second), Recipients: 5, Failed: 1,
+		}))
+
+		stats, err := repo.GetStats()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, stats.Users)
+		assert.Equal(t, 1, stats.InactiveUsers)
+		assert.Equal(t, 1, stats.Artists)
+		assert.Equal(t, 2, stats.ReleasesWithoutCover)
+		assert.Equal(t, map[models.SubscriptionKind]int{
+			models.HistorySubscription:      2,
+			models.WeeklyDigestSubscription: 1,
+		}, stats.Subscribers)
+		assert.Equal(t, []models.YearReleasesStats{
+			{Year: 2023, Albums: 0, Singles: 1},
+			{Year: 2024, Albums: 1, Singles: 1},
+		}, stats.ReleasesByYear)
+
+		assert.Equal(t, 3, stats.LastUpdaterRun.NewReleases)
+		assert.True(t, started.Equal(stats.LastUpdaterRun.StartedAt))
+		assert.Equal(t, time.Minute, stats.LastUpdaterRun.FinishedAt.Sub(stats.LastUpdaterRun.StartedAt))
+		assert.Equal(t, 5, stats.LastDigestRun.Recipients)
+		assert.Equal(t, 1, stats.LastDigestRun.Failed)
+	})
+}
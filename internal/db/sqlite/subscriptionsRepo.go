@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/models"
+)
+
+var _ db.SubscriptionsRepositoryInterface = (*SubscriptionsSqliteRepo)(nil)
+
+const (
+	subscribeStmt = `INSERT OR IGNORE INTO subscriptions (user_id, kind) VALUES (?, ?);`
+
+	unsubscribeStmt = `DELETE FROM subscriptions WHERE user_id = ? AND kind = ?;`
+
+	getUserSubscriptionsQuery = `SELECT kind FROM subscriptions WHERE user_id = ?;`
+
+	getSubscribersQuery = `
+    SELECT u.id, u.username,
+    u.timezone, u.digest_time, u.last_digest_date, u.release_type_filter,
+    u.language
+    FROM subscriptions AS s
+    JOIN users AS u ON s.user_id = u.id
+    WHERE s.kind = ? AND u.is_active = 1;`
+)
+
+type SubscriptionsSqliteRepo struct {
+	DB *sqlx.DB
+}
+
+func NewSubscriptionsSqliteRepo(db *sqlx.DB) *SubscriptionsSqliteRepo {
+	return &SubscriptionsSqliteRepo{db}
+}
+
+func (s *SubscriptionsSqliteRepo) Subscribe(userId int64, kind models.SubscriptionKind) error {
+	_, err := s.DB.Exec(subscribeStmt, userId, kind)
+	if err != nil {
+		return fmt.Errorf("db error subscribe user to %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func (s *SubscriptionsSqliteRepo) Unsubscribe(userId int64, kind models.SubscriptionKind) error {
+	_, err := s.DB.Exec(unsubscribeStmt, userId, kind)
+	if err != nil {
+		return fmt.Errorf("db error unsubscribe user from %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func (s *SubscriptionsSqliteRepo) GetUserSubscriptions(
+	userId int64,
+) ([]models.SubscriptionKind, error) {
+	var kinds []models.SubscriptionKind
+	err := s.DB.Select(&kinds, getUserSubscriptionsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting user subscriptions: %w", err)
+	}
+
+	return kinds, nil
+}
+
+func (s *SubscriptionsSqliteRepo) GetSubscribers(
+	kind models.SubscriptionKind,
+) ([]*models.User, error) {
+	var users []UserSqlite
+	err := s.DB.Select(&users, getSubscribersQuery, kind)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting %s subscribers: %w", kind, err)
+	}
+
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	usersResult := make([]*models.User, 0, len(users))
+	for _, user := range users {
+		usersResult = append(usersResult, user.ToModel())
+	}
+
+	return usersResult, nil
+}
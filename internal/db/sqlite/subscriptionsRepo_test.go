@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/models"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("subscribe and unsubscribe", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+
+		repo := NewSubscriptionsSqliteRepo(db)
+		assert.NoError(t, repo.Subscribe(1, models.HistorySubscription))
+		assert.NoError(t, repo.Subscribe(1, models.WeeklyDigestSubscription))
+
+		kinds, err := repo.GetUserSubscriptions(1)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []models.SubscriptionKind{
+			models.HistorySubscription,
+			models.WeeklyDigestSubscription,
+		}, kinds)
+
+		assert.NoError(t, repo.Unsubscribe(1, models.HistorySubscription))
+		kinds, err = repo.GetUserSubscriptions(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SubscriptionKind{models.WeeklyDigestSubscription}, kinds)
+	})
+
+	t.Run("subscribe twice", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		NewUserSqliteRepo(db).AddUser(models.User{Id: 1, Username: "forsigg"})
+
+		repo := NewSubscriptionsSqliteRepo(db)
+		repo.Subscribe(1, models.HistorySubscription)
+		assert.NoError(t, repo.Subscribe(1, models.HistorySubscription))
+
+		kinds, _ := repo.GetUserSubscriptions(1)
+		assert.Equal(t, []models.SubscriptionKind{models.HistorySubscription}, kinds)
+	})
+}
+
+func TestGetSubscribers(t *testing.T) {
+	t.Run("only subscribers of kind", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		usersRepo := NewUserSqliteRepo(db)
+		historyFan := models.User{Id: 1, Username: "forsigg"}
+		releasesFan := models.User{Id: 2, Username: "kanye"}
+		usersRepo.AddUser(historyFan)
+		usersRepo.AddUser(releasesFan)
+
+		repo := NewSubscriptionsSqliteRepo(db)
+		repo.Subscribe(historyFan.Id, models.HistorySubscription)
+		repo.Subscribe(releasesFan.Id, models.TodayReleasesSubscription)
+
+		users, err := repo.GetSubscribers(models.HistorySubscription)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.User{&historyFan}, users)
+	})
+
+	t.Run("if subscribers not found", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		users, err := NewSubscriptionsSqliteRepo(db).GetSubscribers(models.HistorySubscription)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.Nil(t, users)
+	})
+}
@@ -12,43 +12,66 @@ import (
 
 const (
 	addUserStmt = `
-    INSERT INTO users(id, username, today_subscribe,
-    releases_message_id, releases_page_count, today_releases_message_id, today_releases_page_count)
-    VALUES (?, ?, ?, 0, 0, 0, 0);
+    INSERT INTO users(id, username, timezone, digest_time, language)
+    VALUES (?, ?, ?, ?, ?);
     `
 
 	getUserByUsernameQuery = `
-    SELECT id, username, today_subscribe, releases_message_id,
-    releases_page_count, today_releases_message_id, today_releases_page_count
+    SELECT id, username,
+    timezone, digest_time, last_digest_date, release_type_filter, language
     FROM users
     WHERE username=?;
     `
 
-	setTodaySubscribeStmt = `
+	getUserByIdQuery = `
+    SELECT id, username,
+    timezone, digest_time, last_digest_date, release_type_filter, language
+    FROM users
+    WHERE id=?;
+    `
+
+	getUsersQuery = `
+    SELECT id, username,
+    timezone, digest_time, last_digest_date, release_type_filter, language
+    FROM users
+    WHERE is_active = 1
+    ORDER BY id;
+    `
+
+	setUserTimezoneStmt = `
     UPDATE users
-    SET today_subscribe = ?
+    SET timezone = ?
     WHERE id = ?;
     `
 
-	setReleasesMessageIdStmt = `
+	setUserDigestTimeStmt = `
     UPDATE users
-    SET releases_message_id = ?,
-        releases_page_count = ?
+    SET digest_time = ?
     WHERE id = ?;
     `
 
-	setTodayReleasesMessageIdStmt = `
+	setUserReleaseTypeFilterStmt = `
     UPDATE users
-    SET today_releases_message_id = ?,
-        today_releases_page_count = ?
+    SET release_type_filter = ?
     WHERE id = ?;
     `
 
-	getAllSubscribersQuery = `
-    SELECT id, username, today_subscribe, releases_message_id,
-    releases_page_count, today_releases_message_id, today_releases_page_count
-    FROM users
-    WHERE today_subscribe = true;
+	setUserLanguageStmt = `
+    UPDATE users
+    SET language = ?
+    WHERE id = ?;
+    `
+
+	setUserActiveStmt = `
+    UPDATE users
+    SET is_active = ?
+    WHERE id = ?;
+    `
+
+	setLastDigestDateStmt = `
+    UPDATE users
+    SET last_digest_date = ?
+    WHERE id = ?;
     `
 )
 
@@ -61,13 +84,25 @@ var (
 )
 
 type UserSqlite struct {
-	Id                     int64  `db:"id"`
-	Username               string `db:"username"`
-	IsTodaySubscribe       bool   `db:"today_subscribe"`
-	ReleasesMessageId      int64  `db:"releases_message_id"`
-	ReleasesPageCount      int    `db:"releases_page_count"`
-	TodayReleasesMessageId int64  `db:"today_releases_message_id"`
-	TodayReleasesPageCount int    `db:"today_releases_page_count"`
+	Id                int64  `db:"id"`
+	Username          string `db:"username"`
+	Timezone          string `db:"timezone"`
+	DigestTime        string `db:"digest_time"`
+	LastDigestDate    string `db:"last_digest_date"`
+	ReleaseTypeFilter int    `db:"release_type_filter"`
+	Language          string `db:"language"`
+}
+
+func (u UserSqlite) ToModel() *models.User {
+	return &models.User{
+		Id:                u.Id,
+		Username:          u.Username,
+		Timezone:          u.Timezone,
+		DigestTime:        u.DigestTime,
+		LastDigestDate:    u.LastDigestDate,
+		ReleaseTypeFilter: models.ReleaseType(u.ReleaseTypeFilter),
+		Language:          u.Language,
+	}
 }
 
 type UsersSqliteRepo struct {
@@ -83,7 +118,14 @@ func (u *UsersSqliteRepo) Close() {
 }
 
 func (u *UsersSqliteRepo) AddUser(user models.User) error {
-	_, err := u.DB.Exec(addUserStmt, user.Id, user.Username, user.IsTodaySubscribe)
+	_, err := u.DB.Exec(
+		addUserStmt,
+		user.Id,
+		user.Username,
+		user.Timezone,
+		user.DigestTime,
+		user.Language,
+	)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed:") {
 			return ErrUserAlreadyExists
@@ -107,31 +149,29 @@ func (u *UsersSqliteRepo) GetUserByUsername(username string) (*models.User, erro
 		return nil, ErrUserNotFound
 	}
 
-	return &models.User{
-		Id:                     user[0].Id,
-		Username:               user[0].Username,
-		IsTodaySubscribe:       user[0].IsTodaySubscribe,
-		ReleasesMessageId:      user[0].ReleasesMessageId,
-		ReleasesPageCount:      user[0].ReleasesPageCount,
-		TodayReleasesMessageId: user[0].TodayReleasesMessageId,
-		TodayReleasesPageCount: user[0].TodayReleasesPageCount,
-	}, nil
+	return user[0].ToModel(), nil
 }
 
-func (u *UsersSqliteRepo) SetTodaySubscribe(userId int64, isSubscribe bool) error {
-	_, err := u.DB.Exec(setTodaySubscribeStmt, isSubscribe, userId)
+func (u *UsersSqliteRepo) GetUserById(userId int64) (*models.User, error) {
+	var user []UserSqlite
+	err := u.DB.Select(&user, getUserByIdQuery, userId)
 	if err != nil {
-		return fmt.Errorf("error while trying to set subscribe: %w", err)
+		return nil, fmt.Errorf("error while getting user by id: %w", err)
 	}
 
-	return nil
+	if len(user) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return user[0].ToModel(), nil
 }
 
-func (u *UsersSqliteRepo) GetAllSubscribers() ([]*models.User, error) {
+// GetUsers возвращает пользователей, которые не заблокировали бота.
+func (u *UsersSqliteRepo) GetUsers() ([]*models.User, error) {
 	var users []UserSqlite
-	err := u.DB.Select(&users, getAllSubscribersQuery)
+	err := u.DB.Select(&users, getUsersQuery)
 	if err != nil {
-		return nil, fmt.Errorf("error while getting all subscribers: %w", err)
+		return nil, fmt.Errorf("error while getting users: %w", err)
 	}
 
 	if len(users) == 0 {
@@ -140,43 +180,65 @@ func (u *UsersSqliteRepo) GetAllSubscribers() ([]*models.User, error) {
 
 	usersResult := make([]*models.User, 0, len(users))
 	for _, user := range users {
-		usersResult = append(usersResult, &models.User{
-			Id:                     user.Id,
-			Username:               user.Username,
-			IsTodaySubscribe:       user.IsTodaySubscribe,
-			ReleasesMessageId:      user.ReleasesMessageId,
-			ReleasesPageCount:      user.ReleasesPageCount,
-			TodayReleasesMessageId: user.TodayReleasesMessageId,
-			TodayReleasesPageCount: user.TodayReleasesPageCount,
-		})
+		usersResult = append(usersResult, user.ToModel())
 	}
 
 	return usersResult, nil
 }
 
-func (u *UsersSqliteRepo) SetUserState(
+func (u *UsersSqliteRepo) SetUserTimezone(userId int64, timezone string) error {
+	_, err := u.DB.Exec(setUserTimezoneStmt, timezone, userId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set user timezone: %w", err)
+	}
+
+	return nil
+}
+
+func (u *UsersSqliteRepo) SetUserDigestTime(userId int64, digestTime string) error {
+	_, err := u.DB.Exec(setUserDigestTimeStmt, digestTime, userId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set user digest time: %w", err)
+	}
+
+	return nil
+}
+
+func (u *UsersSqliteRepo) SetLastDigestDate(userId int64, date string) error {
+	_, err := u.DB.Exec(setLastDigestDateStmt, date, userId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set last digest date: %w", err)
+	}
+
+	return nil
+}
+
+func (u *UsersSqliteRepo) SetUserReleaseTypeFilter(
 	userId int64,
-	messageType,
-	messageId,
-	pageCount int,
+	releaseType models.ReleaseType,
 ) error {
-	var stmt string
-	switch messageType {
-	case models.ReleasesMessage:
-		stmt = setReleasesMessageIdStmt
-	case models.TodayReleasesMessage:
-		stmt = setTodayReleasesMessageIdStmt
+	_, err := u.DB.Exec(setUserReleaseTypeFilterStmt, releaseType, userId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set user release type filter: %w", err)
 	}
-	if stmt == "" {
-		return fmt.Errorf(
-			"error while switch case message type in users repo, messageType=%d",
-			messageType,
-		)
+
+	return nil
+}
+
+func (u *UsersSqliteRepo) SetUserLanguage(userId int64, language string) error {
+	_, err := u.DB.Exec(setUserLanguageStmt, language, userId)
+	if err != nil {
+		return fmt.Errorf("error while trying to set user language: %w", err)
 	}
 
-	_, err := u.DB.Exec(stmt, messageId, pageCount, userId)
+	return nil
+}
+
+// SetUserActive выключает рассылки пользователю, заблокировавшему бота, и включает их обратно.
+func (u *UsersSqliteRepo) SetUserActive(userId int64, isActive bool) error {
+	_, err := u.DB.Exec(setUserActiveStmt, isActive, userId)
 	if err != nil {
-		return fmt.Errorf("error while trying to set messageId and pageCount in user: %w", err)
+		return fmt.Errorf("error while trying to set user active: %w", err)
 	}
 
 	return nil
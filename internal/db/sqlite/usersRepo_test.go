@@ -15,9 +15,8 @@ func TestUserSqliteAddUser(t *testing.T) {
 
 		repo := NewUserSqliteRepo(db)
 		userModel := models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: false,
+			Id:       1,
+			Username: "forsigg",
 		}
 		err := repo.AddUser(userModel)
 		assert.NoError(t, err)
@@ -36,9 +35,8 @@ func TestUserSqliteAddUser(t *testing.T) {
 
 		repo := NewUserSqliteRepo(db)
 		user := models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: false,
+			Id:       1,
+			Username: "forsigg",
 		}
 		repo.AddUser(user)
 		err := repo.AddUser(user)
@@ -51,92 +49,151 @@ func TestUserSqliteAddUser(t *testing.T) {
 
 		repo := NewUserSqliteRepo(db)
 		user := models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: false,
+			Id:       1,
+			Username: "forsigg",
 		}
 		_, err := repo.GetUserByUsername(user.Username)
 		assert.ErrorIs(t, err, ErrUserNotFound)
 	})
+}
 
-	t.Run("check set subscribe work correct", func(t *testing.T) {
+func TestUserDigestSettings(t *testing.T) {
+	t.Run("set timezone, digest time and last digest date", func(t *testing.T) {
 		db := prepareTestDb(t)
 		defer removeTestDB(t, db)
 
 		repo := NewUserSqliteRepo(db)
-		user := &models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: false,
+		user := models.User{
+			Id:       1,
+			Username: "forsigg",
 		}
-		repo.AddUser(*user)
+		repo.AddUser(user)
 
-		err := repo.SetTodaySubscribe(user.Id, true)
-		assert.NoError(t, err)
+		assert.NoError(t, repo.SetUserTimezone(user.Id, "Europe/Berlin"))
+		assert.NoError(t, repo.SetUserDigestTime(user.Id, "09:30"))
+		assert.NoError(t, repo.SetLastDigestDate(user.Id, "2024-08-11"))
 
-		userDb, _ := repo.GetUserByUsername(user.Username)
-		assert.Equal(t, userDb.IsTodaySubscribe, true)
+		userFromDb, _ := repo.GetUserByUsername(user.Username)
+		assert.Equal(t, "Europe/Berlin", userFromDb.Timezone)
+		assert.Equal(t, "09:30", userFromDb.DigestTime)
+		assert.Equal(t, "2024-08-11", userFromDb.LastDigestDate)
 	})
 }
 
-func TestGetAllSubscribers(t *testing.T) {
-	t.Run("success case", func(t *testing.T) {
+func TestSetUserLanguage(t *testing.T) {
+	t.Run("language is saved on add and can be changed", func(t *testing.T) {
 		db := prepareTestDb(t)
 		defer removeTestDB(t, db)
 
 		repo := NewUserSqliteRepo(db)
 		user := models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: true,
+			Id:       1,
+			Username: "forsigg",
+			Language: "en",
 		}
 		repo.AddUser(user)
 
-		users, err := repo.GetAllSubscribers()
-		if err != nil {
-			t.Fatal(err)
-		}
+		userFromDb, _ := repo.GetUserByUsername(user.Username)
+		assert.Equal(t, "en", userFromDb.Language)
+
+		assert.NoError(t, repo.SetUserLanguage(user.Id, "ru"))
+		userFromDb, _ = repo.GetUserByUsername(user.Username)
+		assert.Equal(t, "ru", userFromDb.Language)
+	})
+}
+
+func TestGetUserById(t *testing.T) {
+	t.Run("user without username is found by id", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewUserSqliteRepo(db)
+		repo.AddUser(models.User{Id: 1})
 
+		userFromDb, err := repo.GetUserById(1)
 		assert.NoError(t, err)
-		assert.Equal(t, 1, len(users))
-		assert.Equal(t, user, *users[0])
+		assert.Equal(t, int64(1), userFromDb.Id)
 	})
 
-	t.Run("if subscribers not found", func(t *testing.T) {
+	t.Run("user not found", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		_, err := NewUserSqliteRepo(db).GetUserById(1)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestGetUsers(t *testing.T) {
+	t.Run("all users ordered by id", func(t *testing.T) {
 		db := prepareTestDb(t)
 		defer removeTestDB(t, db)
 
 		repo := NewUserSqliteRepo(db)
+		repo.AddUser(models.User{Id: 2, Username: "kanye", Language: "en"})
+		repo.AddUser(models.User{Id: 1, Username: "forsigg", Language: "ru"})
 
-		users, err := repo.GetAllSubscribers()
+		users, err := repo.GetUsers()
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, int64(1), users[0].Id)
+		assert.Equal(t, "en", users[1].Language)
+	})
+
+	t.Run("no users", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		_, err := NewUserSqliteRepo(db).GetUsers()
 		assert.ErrorIs(t, err, ErrUserNotFound)
-		assert.Nil(t, users)
 	})
 }
 
-func TestSetUserState(t *testing.T) {
-	t.Run("success case", func(t *testing.T) {
+func TestSetUserActive(t *testing.T) {
+	t.Run("inactive users get no messages", func(t *testing.T) {
 		db := prepareTestDb(t)
 		defer removeTestDB(t, db)
 
 		repo := NewUserSqliteRepo(db)
-		user := models.User{
-			Id:               1,
-			Username:         "forsigg",
-			IsTodaySubscribe: true,
-		}
-		repo.AddUser(user)
+		repo.AddUser(models.User{Id: 1, Username: "forsigg"})
+		repo.AddUser(models.User{Id: 2, Username: "blocked"})
+		subscriptionsRepo := NewSubscriptionsSqliteRepo(db)
+		subscriptionsRepo.Subscribe(1, models.HistorySubscription)
+		subscriptionsRepo.Subscribe(2, models.HistorySubscription)
+		followsRepo := NewFollowsSqliteRepo(db)
+		artistId, _ := NewArtistSqliteRepo(db).AddArtist("21 Savage")
+		followsRepo.FollowArtist(2, artistId)
+
+		assert.NoError(t, repo.SetUserActive(2, false))
+
+		users, err := repo.GetUsers()
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, int64(1), users[0].Id)
 
-		err := repo.SetUserState(
-			user.Id,
-			models.TodayReleasesMessage,
-			1,
-			5,
-		)
+		subscribers, err := subscriptionsRepo.GetSubscribers(models.HistorySubscription)
 		assert.NoError(t, err)
+		assert.Len(t, subscribers, 1)
 
-		userFromDb, _ := repo.GetUserByUsername(user.Username)
-		assert.Equal(t, userFromDb.TodayReleasesMessageId, int64(1))
-		assert.Equal(t, userFromDb.TodayReleasesPageCount, 5)
+		followers, _ := followsRepo.GetArtistFollowers(artistId)
+		assert.Empty(t, followers)
+
+		// пользователь по-прежнему находится по id, чтобы /start мог его вернуть
+		_, err = repo.GetUserById(2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("user is back after /start", func(t *testing.T) {
+		db := prepareTestDb(t)
+		defer removeTestDB(t, db)
+
+		repo := NewUserSqliteRepo(db)
+		repo.AddUser(models.User{Id: 1, Username: "forsigg"})
+		repo.SetUserActive(1, false)
+		repo.SetUserActive(1, true)
+
+		users, err := repo.GetUsers()
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
 	})
 }
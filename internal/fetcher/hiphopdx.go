@@ -3,7 +3,6 @@ package fetcher
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -47,10 +46,15 @@ func (f *HipHopDXFetcher) Close() {
 }
 
 func (f *HipHopDXFetcher) GetSinglesPosts(year int) ([]models.Post, error) {
+	return f.GetMonthSinglesPosts(year, 0)
+}
+
+// GetMonthSinglesPosts при month = 0 возвращает синглы за весь год.
+func (f *HipHopDXFetcher) GetMonthSinglesPosts(year int, month time.Month) ([]models.Post, error) {
 	log.Println("start getting singles posts...")
 	posts := make([]models.Post, 0)
 	for page := 1; true; page++ {
-		url := f.buildSinglesUrl(year, 0, page)
+		url := f.buildSinglesUrl(year, month, page)
 		resp, err := f.DoRequest(url)
 		if err != nil {
 			return nil, err
@@ -64,7 +68,10 @@ func (f *HipHopDXFetcher) GetSinglesPosts(year int) ([]models.Post, error) {
 				return nil, err
 			}
 		}
-		respPosts := f.parseResponse(resp)
+		respPosts, err := f.parseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
 		if len(respPosts) == 0 {
 			break
 		}
@@ -96,7 +103,10 @@ func (f *HipHopDXFetcher) GetReleasesPosts(
 				return nil, err
 			}
 		}
-		postsBatch := f.parseResponse(resp)
+		postsBatch, err := f.parseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
 
 		if len(postsBatch) == 0 {
 			break
@@ -108,17 +118,22 @@ func (f *HipHopDXFetcher) GetReleasesPosts(
 	return posts, nil
 }
 
-func (f *HipHopDXFetcher) parseResponse(resp *http.Response) []models.Post {
+// parseResponse разбирает ответ HipHopDX, сбой сайта возвращается ошибкой,
+// чтобы обновление пропустило этот год, а не останавливало бота.
+func (f *HipHopDXFetcher) parseResponse(resp *http.Response) ([]models.Post, error) {
 	defer resp.Body.Close()
 	log.Println("parsing response...")
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status of hiphopdx response: %s", resp.Status)
+	}
+
 	var p ReleaseResponse
 	err := json.NewDecoder(resp.Body).Decode(&p)
 	if err != nil {
-		bodyStr, _ := io.ReadAll(resp.Body)
-		log.Fatalf("error while parsing response: %s \njson body: %s", err, bodyStr)
+		return nil, fmt.Errorf("error while parsing response: %w", err)
 	}
 
-	return p.Data.Posts
+	return p.Data.Posts, nil
 }
 
 func (f *HipHopDXFetcher) buildReleasesUrl(year int, month time.Month, page int) string {
@@ -144,6 +159,9 @@ func (f *HipHopDXFetcher) buildSinglesUrl(year int, month time.Month, page int)
 		"post_status":    {"publish,future"},
 		"year":           {strconv.Itoa(year)},
 	}
+	if month != 0 {
+		queries["monthnum"] = []string{strconv.Itoa(int(month))}
+	}
 	return BuildUrl(queries)
 }
 
@@ -216,7 +216,8 @@ func TestParseResponse(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			resp := getJsonResponse(t, c.json)
-			result := fetcher.parseResponse(resp)
+			result, err := fetcher.parseResponse(resp)
+			assert.NoError(t, err)
 
 			if !reflect.DeepEqual(result, c.expected) {
 				t.Errorf("expected %v, got %v", c.expected, result)
@@ -2,8 +2,8 @@ package fetcher
 
 import (
 	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -23,7 +23,8 @@ const (
 
 	dateLayout = "Jan. 2 2006"
 
-	twoDaysMinutes = float64((60 * 24) * 2)
+	// если за день событий нет, ищем за столько предыдущих дней
+	eventsDaysBack = 2
 )
 
 var (
@@ -51,55 +52,86 @@ func (f *TodayHipHopFetcher) Close() {
 }
 
 func (f *TodayHipHopFetcher) GetTodayEvents() ([]*models.TodayPost, error) {
-	htmlBody := f.getHTML()
-	doc := f.parseResponse(htmlBody)
-	post, err := f.getPostsFromDoc(doc, time.Now().UTC())
+	return f.GetEventsByDate(time.Now().UTC())
+}
 
-	return post, err
+// GetEventsByDate возвращает события за указанный день, если за него
+// ничего нет - за ближайший предыдущий день.
+func (f *TodayHipHopFetcher) GetEventsByDate(date time.Time) ([]*models.TodayPost, error) {
+	htmlBody, err := f.getHTML()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := f.parseResponse(htmlBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.getPostsFromDoc(doc, date)
 }
 
-func (f *TodayHipHopFetcher) getHTML() io.ReadCloser {
+func (f *TodayHipHopFetcher) getHTML() (io.ReadCloser, error) {
 	req, err := http.NewRequest(http.MethodGet, todayHipHopHistoryUrl, nil)
 	if err != nil {
-		log.Fatalf("error while creating request for today history site: %s", err)
+		return nil, fmt.Errorf("error while creating request for today history site: %w", err)
 	}
 	f.currentReq = req
 	resp, err := f.Client.Do(req)
 	f.currentReq = nil
 	if err != nil {
-		log.Fatalf("error while getting today history html: %s", err)
+		return nil, fmt.Errorf("error while getting today history html: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status of today history site: %s", resp.Status)
 	}
 
-	return resp.Body
+	return resp.Body, nil
 }
 
-func (f *TodayHipHopFetcher) parseResponse(htmlBody io.ReadCloser) *goquery.Document {
+func (f *TodayHipHopFetcher) parseResponse(htmlBody io.ReadCloser) (*goquery.Document, error) {
 	defer htmlBody.Close()
 	doc, err := goquery.NewDocumentFromReader(htmlBody)
 	if err != nil {
-		log.Fatalf("error while parsing html: %s", err)
+		return nil, fmt.Errorf("error while parsing html: %w", err)
 	}
 
-	return doc
+	return doc, nil
 }
 
+// getPostsFromDoc ищет события за date, если их нет - за предыдущие eventsDaysBack дней.
 func (f *TodayHipHopFetcher) getPostsFromDoc(
 	doc *goquery.Document,
-	now time.Time,
+	date time.Time,
 ) ([]*models.TodayPost, error) {
-	if time.Now().UTC().Sub(now).Minutes() > twoDaysMinutes {
-		return nil, ErrPostsNotFound
+	for daysBack := 0; daysBack <= eventsDaysBack; daysBack++ {
+		posts, err := f.getDayPostsFromDoc(doc, date.AddDate(0, 0, -daysBack))
+		if err != nil {
+			return nil, err
+		}
+		if len(posts) != 0 {
+			return posts, nil
+		}
 	}
 
+	return nil, ErrPostsNotFound
+}
+
+func (f *TodayHipHopFetcher) getDayPostsFromDoc(
+	doc *goquery.Document,
+	day time.Time,
+) ([]*models.TodayPost, error) {
 	var posts []*models.TodayPost
-	doc.Find(divPostSelector).Each(func(i int, s *goquery.Selection) {
+	var err error
+	doc.Find(divPostSelector).EachWithBreak(func(i int, s *goquery.Selection) bool {
 		date := s.Find(dateLinkSelector).Text()
-		tt, err := time.Parse(dateLayout, date)
-		if err != nil {
-			log.Fatalf("error while parsing datetime from today events: %v", err)
+		tt, parseErr := time.Parse(dateLayout, date)
+		if parseErr != nil {
+			err = fmt.Errorf("error while parsing datetime from today events: %w", parseErr)
+			return false
 		}
 
-		if tt.Month() == now.Month() && tt.Day() == now.Day() && tt.Year() == now.Year() {
+		if tt.Month() == day.Month() && tt.Day() == day.Day() && tt.Year() == day.Year() {
 			text := s.Find(divClassText).Find("p").Text()
 			text = strings.TrimPrefix(text, "Today in Hip Hop History:")
 			image, _ := s.Find(aClassMediaPhotoImageSelector).Attr("data-big-photo")
@@ -109,11 +141,8 @@ func (f *TodayHipHopFetcher) getPostsFromDoc(
 				Url:  image,
 			})
 		}
+		return true
 	})
 
-	if len(posts) == 0 {
-		return f.getPostsFromDoc(doc, now.AddDate(0, 0, -1))
-	}
-
-	return posts, nil
+	return posts, err
 }
@@ -43,7 +43,9 @@ func TestGetHTML(t *testing.T) {
 			nil,
 		}
 		want := []byte(htmlBody)
-		got, err := io.ReadAll(fetcher.getHTML())
+		htmlB, err := fetcher.getHTML()
+		assert.NoError(t, err)
+		got, err := io.ReadAll(htmlB)
 		assert.NoError(t, err)
 		assert.Equal(t, want, got)
 	})
@@ -58,7 +60,9 @@ func TestGetHTML(t *testing.T) {
 			nil,
 		}
 		want := []byte("")
-		got, err := io.ReadAll(fetcher.getHTML())
+		htmlB, err := fetcher.getHTML()
+		assert.NoError(t, err)
+		got, err := io.ReadAll(htmlB)
 		assert.NoError(t, err)
 		assert.Equal(t, want, got)
 	})
@@ -77,8 +81,10 @@ func TestParseEventsResponse(t *testing.T) {
 		htmlReader := strings.NewReader(htmlBody)
 		want, _ := goquery.NewDocumentFromReader(htmlReader)
 
-		htmlB := fetcher.getHTML()
-		got := fetcher.parseResponse(htmlB)
+		htmlB, err := fetcher.getHTML()
+		assert.NoError(t, err)
+		got, err := fetcher.parseResponse(htmlB)
+		assert.NoError(t, err)
 
 		assert.Equal(t, want, got)
 	})
@@ -95,8 +101,10 @@ func TestParseEventsResponse(t *testing.T) {
 		htmlReader := strings.NewReader("")
 		want, _ := goquery.NewDocumentFromReader(htmlReader)
 
-		htmlB := fetcher.getHTML()
-		got := fetcher.parseResponse(htmlB)
+		htmlB, err := fetcher.getHTML()
+		assert.NoError(t, err)
+		got, err := fetcher.parseResponse(htmlB)
+		assert.NoError(t, err)
 
 		assert.Equal(t, want, got)
 	})
@@ -120,8 +128,8 @@ func TestGetPostFromDoc(t *testing.T) {
 			},
 		}
 
-		htmlB := fetcher.getHTML()
-		doc := fetcher.parseResponse(htmlB)
+		htmlB, _ := fetcher.getHTML()
+		doc, _ := fetcher.parseResponse(htmlB)
 		got, err := fetcher.getPostsFromDoc(doc, freezeTime)
 
 		assert.NoError(t, err)
@@ -138,8 +146,8 @@ func TestGetPostFromDoc(t *testing.T) {
 			nil,
 		}
 		var want []*models.TodayPost
-		htmlB := fetcher.getHTML()
-		doc := fetcher.parseResponse(htmlB)
+		htmlB, _ := fetcher.getHTML()
+		doc, _ := fetcher.parseResponse(htmlB)
 
 		freezeTime := time.Date(2024, time.August, 11, 0, 0, 0, 0, time.UTC)
 		got, err := fetcher.getPostsFromDoc(doc, freezeTime)
@@ -163,11 +171,37 @@ func TestGetPostFromDoc(t *testing.T) {
 			nil,
 		}
 		var want []*models.TodayPost
-		htmlB := fetcher.getHTML()
-		doc := fetcher.parseResponse(htmlB)
+		htmlB, _ := fetcher.getHTML()
+		doc, _ := fetcher.parseResponse(htmlB)
 		got, err := fetcher.getPostsFromDoc(doc, freezeTime)
 
 		assert.ErrorIs(t, err, ErrPostsNotFound)
 		assert.Equal(t, want, got)
 	})
 }
+
+func TestGetPostFromDocDaysBack(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlBody))
+	assert.NoError(t, err)
+	fetcher := TodayHipHopFetcher{}
+
+	t.Run("previous day", func(t *testing.T) {
+		got, err := fetcher.getPostsFromDoc(doc, freezeTime.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("too far from events", func(t *testing.T) {
+		_, err := fetcher.getPostsFromDoc(doc, freezeTime.AddDate(0, 0, eventsDaysBack+1))
+		assert.ErrorIs(t, err, ErrPostsNotFound)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		doc, _ := goquery.NewDocumentFromReader(strings.NewReader(
+			`<div class="post"><div class="date"><a href="1">yesterday</a></div></div>`,
+		))
+		_, err := fetcher.getPostsFromDoc(doc, freezeTime)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPostsNotFound)
+	})
+}
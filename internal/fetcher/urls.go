@@ -1,18 +1,12 @@
 package fetcher
 
 import (
-	"log"
 	"net/url"
 )
 
 func BuildUrl(queries map[string][]string) string {
-	url, err := url.Parse(postsUrl)
-	if err != nil {
-		log.Fatal(err)
-	}
-	AddQueriesToUrl(url, queries)
-
-	return url.String()
+	// postsUrl - константа без query, поэтому достаточно дописать параметры
+	return postsUrl + "?" + url.Values(queries).Encode()
 }
 
 func AddQueriesToUrl(url_link *url.URL, queries map[string][]string) {
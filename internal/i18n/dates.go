@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"fmt"
+	"time"
+)
+
+var monthNames = map[Lang][12]string{
+	Ru: {
+		"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+		"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+	},
+	En: {
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	},
+}
+
+// в русском в датах месяц стоит в родительном падеже: "11 августа"
+var monthNamesInDate = map[Lang][12]string{
+	Ru: {
+		"января", "февраля", "марта", "апреля", "мая", "июня",
+		"июля", "августа", "сентября", "октября", "ноября", "декабря",
+	},
+	En: monthNames[En],
+}
+
+var shortMonthNames = map[Lang][12]string{
+	Ru: {
+		"Янв", "Фев", "Мар", "Апр", "Май", "Июн",
+		"Июл", "Авг", "Сен", "Окт", "Ноя", "Дек",
+	},
+	En: {
+		"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+	},
+}
+
+var weekdayNames = map[Lang][7]string{
+	Ru: {"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	En: {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+func langOrDefault(lang Lang) Lang {
+	if _, ok := monthNames[lang]; ok {
+		return lang
+	}
+	return DefaultLang
+}
+
+// MonthName - название месяца для заголовков, например "Март 2024".
+func MonthName(lang Lang, month time.Month) string {
+	return monthNames[langOrDefault(lang)][month-1]
+}
+
+func ShortMonthName(lang Lang, month time.Month) string {
+	return shortMonthNames[langOrDefault(lang)][month-1]
+}
+
+func WeekdayName(lang Lang, weekday time.Weekday) string {
+	return weekdayNames[langOrDefault(lang)][weekday]
+}
+
+// FormatDayMonth форматирует дату без года: "11 августа", "11 August".
+func FormatDayMonth(lang Lang, date time.Time) string {
+	return fmt.Sprintf("%d %s", date.Day(), monthNamesInDate[langOrDefault(lang)][date.Month()-1])
+}
+
+// FormatDate форматирует дату с годом: "11 августа 2024", "11 August 2024".
+func FormatDate(lang Lang, date time.Time) string {
+	return fmt.Sprintf("%s %d", FormatDayMonth(lang, date), date.Year())
+}
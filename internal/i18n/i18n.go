@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Lang string
+
+const (
+	Ru Lang = "ru"
+	En Lang = "en"
+
+	// DefaultLang - язык, на котором бот отвечал до появления переводов
+	DefaultLang = Ru
+)
+
+var Supported = []Lang{Ru, En}
+
+// FromCode выбирает язык по language_code из Telegram, например "en-US".
+// Пустой код - язык по умолчанию, любой неподдерживаемый - английский.
+func FromCode(code string) Lang {
+	if code == "" {
+		return DefaultLang
+	}
+
+	lang := Lang(strings.ToLower(strings.SplitN(code, "-", 2)[0]))
+	if slices.Contains(Supported, lang) {
+		return lang
+	}
+
+	return En
+}
+
+// Parse проверяет, что язык поддерживается. Пустая строка - язык еще не выбран.
+func Parse(value string) (Lang, bool) {
+	lang := Lang(value)
+	return lang, slices.Contains(Supported, lang)
+}
+
+// Key - идентификатор строки в каталоге, одинаковый для всех языков.
+type Key string
+
+type Bundle map[Key]string
+
+type Catalog struct {
+	bundles  map[Lang]Bundle
+	fallback Lang
+}
+
+func NewCatalog(fallback Lang, bundles map[Lang]Bundle) *Catalog {
+	return &Catalog{
+		bundles:  bundles,
+		fallback: fallback,
+	}
+}
+
+// T возвращает строку на языке lang, если перевода нет - на запасном языке,
+// а если нет и его - сам ключ, чтобы пропуск был заметен, но не ломал ответ.
+// Аргументы подставляются через fmt.Sprintf.
+func (c *Catalog) T(lang Lang, key Key, args ...any) string {
+	text, ok := c.bundles[lang][key]
+	if !ok {
+		text, ok = c.bundles[c.fallback][key]
+	}
+	if !ok {
+		text = string(key)
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Match ищет среди keys тот, текст которого на любом из языков совпадает с text.
+// Нужен для кнопок reply-клавиатуры: Telegram присылает только их подпись.
+func (c *Catalog) Match(text string, keys []Key) (Key, bool) {
+	for _, key := range keys {
+		for _, bundle := range c.bundles {
+			if bundleText, ok := bundle[key]; ok && bundleText == text {
+				return key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Missing возвращает ключи, которые есть в каком-то языке, но не переведены на lang.
+func (c *Catalog) Missing(lang Lang) []Key {
+	var missing []Key
+	for _, bundle := range c.bundles {
+		for key := range bundle {
+			if _, ok := c.bundles[lang][key]; !ok && !slices.Contains(missing, key) {
+				missing = append(missing, key)
+			}
+		}
+	}
+
+	slices.Sort(missing)
+	return missing
+}
@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromCode(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected Lang
+	}{
+		{"ru", Ru},
+		{"en", En},
+		{"en-US", En},
+		{"de", En},
+		{"", DefaultLang},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FromCode(tc.code))
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := NewCatalog(Ru, map[Lang]Bundle{
+		Ru: {"hello": "Привет, %s", "today": "Сегодня"},
+		En: {"hello": "Hello, %s"},
+	})
+
+	t.Run("translate with args", func(t *testing.T) {
+		assert.Equal(t, "Hello, Kendrick", catalog.T(En, "hello", "Kendrick"))
+		assert.Equal(t, "Привет, Kendrick", catalog.T(Ru, "hello", "Kendrick"))
+	})
+
+	t.Run("fallback language and unknown key", func(t *testing.T) {
+		assert.Equal(t, "Сегодня", catalog.T(En, "today"))
+		assert.Equal(t, "unknown", catalog.T(En, "unknown"))
+	})
+
+	t.Run("match text in any language", func(t *testing.T) {
+		key, ok := catalog.Match("Сегодня", []Key{"hello", "today"})
+		assert.True(t, ok)
+		assert.Equal(t, Key("today"), key)
+
+		_, ok = catalog.Match("Today", []Key{"hello", "today"})
+		assert.False(t, ok)
+	})
+
+	t.Run("missing translations", func(t *testing.T) {
+		assert.Equal(t, []Key{"today"}, catalog.Missing(En))
+		assert.Empty(t, catalog.Missing(Ru))
+	})
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, time.August, 11, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "11 августа 2024", FormatDate(Ru, date))
+	assert.Equal(t, "11 August 2024", FormatDate(En, date))
+	assert.Equal(t, "Август", MonthName(Ru, date.Month()))
+	assert.Equal(t, "воскресенье", WeekdayName(Ru, date.Weekday()))
+}
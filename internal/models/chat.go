@@ -0,0 +1,23 @@
+package models
+
+// Chat - группа или канал, куда бот присылает рассылки. Настройки хранятся
+// отдельно от пользователей, пустые значения означают настройки по умолчанию.
+type Chat struct {
+	Id int64
+	// Title - название чата на момент последнего обновления
+	Title string
+	// Type - тип чата из Telegram: group, supergroup или channel
+	Type           string
+	Timezone       string
+	DigestTime     string
+	LastDigestDate string
+	Language       string
+}
+
+// ChatSubscriptionKinds - рассылки, которые можно включить в чате.
+// Уведомления об отслеживаемых исполнителях бывают только личными.
+var ChatSubscriptionKinds = []SubscriptionKind{
+	HistorySubscription,
+	TodayReleasesSubscription,
+	WeeklyDigestSubscription,
+}
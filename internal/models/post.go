@@ -1,7 +1,6 @@
 package models
 
 import (
-	"fmt"
 	"strings"
 
 	"hip-hop-geek/internal/types"
@@ -30,19 +29,20 @@ func (p Post) Artist() string {
 	return strings.TrimSuffix(strings.Split(p.QueryField, divider)[0], " ")
 }
 
-func (p Post) Title() string {
+// Title возвращает название релиза из заголовка поста "Исполнитель - Название",
+// false - если в заголовке нет разделителя.
+func (p Post) Title() (string, bool) {
 	divider := " - "
-	if strings.Contains(p.QueryField, "–") {
+	if strings.Contains(p.QueryField, " – ") {
 		divider = " – "
 	}
 
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println(p.QueryField)
-			panic(r)
-		}
-	}()
-	return strings.TrimPrefix(strings.Split(p.QueryField, divider)[1], " ")
+	_, title, ok := strings.Cut(p.QueryField, divider)
+	if !ok {
+		return "", false
+	}
+
+	return strings.TrimPrefix(title, " "), true
 }
 
 func (p Post) Query() string {
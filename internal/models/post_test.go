@@ -25,7 +25,7 @@ func TestPost(t *testing.T) {
 
 	t.Run("check Title work correct", func(t *testing.T) {
 		expected := "American Dream"
-		got := post.Title()
+		got, _ := post.Title()
 
 		if got != expected {
 			t.Errorf("incorrect title, got '%s', want '%s'", got, expected)
@@ -55,7 +55,7 @@ func TestPost(t *testing.T) {
 		expectedTitle := "American Dream"
 
 		gotArtist := post.Artist()
-		gotTitle := post.Title()
+		gotTitle, _ := post.Title()
 
 		if gotArtist != expectedArtist || gotTitle != expectedTitle {
 			t.Errorf(
@@ -79,7 +79,7 @@ func TestPost(t *testing.T) {
 		log.Println(strings.Contains(postCheck.QueryField, "–"))
 
 		gotArtist := postCheck.Artist()
-		gotTitle := postCheck.Title()
+		gotTitle, _ := postCheck.Title()
 
 		if gotArtist != expectedArtist || gotTitle != expectedTitle {
 			t.Errorf(
@@ -91,4 +91,23 @@ func TestPost(t *testing.T) {
 			)
 		}
 	})
+
+	t.Run("check Title without divider", func(t *testing.T) {
+		postCheck := Post{1, "Kanye West Vultures", types.NewCustomDate(2024, time.January, 1)}
+
+		got, ok := postCheck.Title()
+		if ok || got != "" {
+			t.Errorf("expected no title, got '%s'", got)
+		}
+	})
+
+	t.Run("check – inside title", func(t *testing.T) {
+		postCheck := Post{1, "A$AP Rocky - Don't Be Dumb–Deluxe", types.NewCustomDate(2024, time.January, 1)}
+		expected := "Don't Be Dumb–Deluxe"
+
+		got, ok := postCheck.Title()
+		if !ok || got != expected {
+			t.Errorf("incorrect title, got '%s', want '%s'", got, expected)
+		}
+	})
 }
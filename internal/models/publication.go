@@ -0,0 +1,10 @@
+package models
+
+// Publication - пост, который бот опубликовал в канал за дату Date.
+// MessageId равен 0, если публиковать было нечего, например в день без релизов.
+type Publication struct {
+	ChannelId int64
+	Kind      SubscriptionKind
+	Date      string
+	MessageId int
+}
@@ -9,7 +9,9 @@ import (
 type ReleaseType int
 
 const (
-	Album = iota + 1
+	// AnyType - фильтр по типу релиза выключен
+	AnyType = iota
+	Album
 	Single
 )
 
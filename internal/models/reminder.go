@@ -0,0 +1,10 @@
+package models
+
+// Reminder - напоминание пользователю о выходе релиза. Дата не хранится,
+// она берется из релиза, поэтому перенос релиза переносит и напоминание.
+// LastAttemptDate - день последней неудачной отправки по часовому поясу пользователя.
+type Reminder struct {
+	User            *User
+	ReleaseId       int
+	LastAttemptDate string
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// UpdaterRun - успешное обновление релизов.
+type UpdaterRun struct {
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	NewReleases int
+}
+
+// DigestRun - итог рассылки, отправленной за одну проверку планировщика.
+type DigestRun struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Recipients int
+	Failed     int
+}
+
+type YearReleasesStats struct {
+	Year    int
+	Albums  int
+	Singles int
+}
+
+// Stats - сводка для команды /stats администратора.
+type Stats struct {
+	Users                int
+	Chats                int
+	Artists              int
+	Subscribers          map[SubscriptionKind]int
+	ReleasesByYear       []YearReleasesStats
+	ReleasesWithoutCover int
+	// InactiveUsers - пользователи, заблокировавшие бота, они входят в Users
+	InactiveUsers int
+	// LastUpdaterRun и LastDigestRun равны nil, если запусков еще не было
+	LastUpdaterRun *UpdaterRun
+	LastDigestRun  *DigestRun
+}
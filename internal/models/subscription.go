@@ -0,0 +1,21 @@
+package models
+
+type SubscriptionKind string
+
+const (
+	// HistorySubscription - ежедневное событие из истории хип хопа
+	HistorySubscription SubscriptionKind = "history"
+	// TodayReleasesSubscription - ежедневная подборка релизов за сегодня
+	TodayReleasesSubscription SubscriptionKind = "today_releases"
+	// WeeklyDigestSubscription - релизы на неделю вперед, приходят по понедельникам
+	WeeklyDigestSubscription SubscriptionKind = "weekly_digest"
+	// ArtistAlertsSubscription - новые релизы исполнителей, за которыми следит пользователь
+	ArtistAlertsSubscription SubscriptionKind = "artist_alerts"
+)
+
+var AllSubscriptionKinds = []SubscriptionKind{
+	HistorySubscription,
+	TodayReleasesSubscription,
+	WeeklyDigestSubscription,
+	ArtistAlertsSubscription,
+}
@@ -1,18 +1,16 @@
 package models
 
-type MessageIdType int
-
-const (
-	ReleasesMessage = iota
-	TodayReleasesMessage
-)
-
 type User struct {
-	Id                     int64
-	Username               string
-	IsTodaySubscribe       bool
-	ReleasesMessageId      int64
-	ReleasesPageCount      int
-	TodayReleasesMessageId int64
-	TodayReleasesPageCount int
+	Id       int64
+	Username string
+	// Timezone - название часового пояса из базы IANA, пустая строка - пояс сервера
+	Timezone string
+	// DigestTime - время ежедневной рассылки в формате 15:04, пустая строка - время по умолчанию
+	DigestTime string
+	// LastDigestDate - дата последней рассылки в часовом поясе пользователя
+	LastDigestDate string
+	// ReleaseTypeFilter - какие релизы показывать в списках, AnyType - все
+	ReleaseTypeFilter ReleaseType
+	// Language - язык интерфейса, пустая строка - еще не выбран
+	Language string
 }
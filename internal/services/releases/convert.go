@@ -2,7 +2,6 @@ package releases
 
 import (
 	"log"
-	"strings"
 	"time"
 
 	"hip-hop-geek/internal/db"
@@ -18,7 +17,8 @@ func ConvertPostsToReleases(
 
 	for _, post := range posts {
 		// skip releases without divider symbol
-		if !strings.Contains(post.QueryField, " - ") && !strings.Contains(post.QueryField, " – ") {
+		title, ok := post.Title()
+		if !ok {
 			log.Printf("release %s skipped", post.QueryField)
 			continue
 		}
@@ -29,7 +29,7 @@ func ConvertPostsToReleases(
 				Name: post.Artist(),
 			},
 			Type:     releaseType,
-			Title:    post.Title(),
+			Title:    title,
 			OutDate:  post.ReleaseDate(),
 			CoverUrl: models.CoverUrl{},
 		})
@@ -2,7 +2,7 @@ package releases
 
 import (
 	"errors"
-	"log"
+	"fmt"
 	"time"
 
 	"hip-hop-geek/internal/db"
@@ -19,11 +19,13 @@ type CoverBook interface {
 
 type EventsFetcher interface {
 	GetTodayEvents() ([]*models.TodayPost, error)
+	GetEventsByDate(date time.Time) ([]*models.TodayPost, error)
 	Close()
 }
 
 type ReleaseFetcher interface {
 	GetSinglesPosts(year int) ([]models.Post, error)
+	GetMonthSinglesPosts(year int, month time.Month) ([]models.Post, error)
 	GetReleasesPosts(year int, month time.Month) ([]models.Post, error)
 	Close()
 }
@@ -67,6 +69,10 @@ func (h *HipHopService) GetTodayEvents() ([]*models.TodayPost, error) {
 	return h.EventsFetcher.GetTodayEvents()
 }
 
+func (h *HipHopService) GetEventsByDate(date time.Time) ([]*models.TodayPost, error) {
+	return h.EventsFetcher.GetEventsByDate(date)
+}
+
 func (h *HipHopService) FetchReleases(year int) ([]models.Release, error) {
 	releases := make([]models.Release, 0, 5)
 	for monthNum := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12} {
@@ -89,35 +95,51 @@ func (h *HipHopService) FetchSingles(year int) ([]models.Release, error) {
 	return ConvertPostsToReleases(singlesPosts, models.Single), nil
 }
 
+// FetchMonthReleases забирает с HipHopDX альбомы и синглы одного месяца.
+func (h *HipHopService) FetchMonthReleases(year int, month time.Month) ([]models.Release, error) {
+	posts, err := h.ReleaseFetcher.GetReleasesPosts(year, month)
+	if err != nil {
+		return nil, err
+	}
+	releases := ConvertPostsToReleases(posts, models.Album)
+
+	singlesPosts, err := h.ReleaseFetcher.GetMonthSinglesPosts(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(releases, ConvertPostsToReleases(singlesPosts, models.Single)...), nil
+}
+
+// GetMonthReleases возвращает релизы месяца, если их нет - пустой список без ошибки.
 func (h *HipHopService) GetMonthReleases(
 	year int,
 	month time.Month,
+	releaseType models.ReleaseType,
 	limit,
 	offset int,
-) []models.Release {
-	releases, err := h.GetReleasesByMonth(month, year, limit, offset)
+) ([]models.Release, error) {
+	releases, err := h.GetReleasesByMonth(month, year, releaseType, limit, offset)
+	if errors.Is(err, sqlite.ErrReleasesNotFound) {
+		return nil, nil
+	}
 	if err != nil {
-		if errors.Is(err, sqlite.ErrReleasesNotFound) {
-			return nil
-		} else {
-			log.Fatal(err)
-		}
+		return nil, fmt.Errorf("error while getting releases of %d-%02d: %w", year, month, err)
 	}
-	rels := ConvertDbReleaseToModelRelease(releases)
-	return rels
+
+	return ConvertDbReleaseToModelRelease(releases), nil
 }
 
-func (h *HipHopService) GetAllYearReleases(year, limit, offset int) []models.Release {
+func (h *HipHopService) GetAllYearReleases(year, limit, offset int) ([]models.Release, error) {
 	releases, err := h.GetReleasesByYear(year, limit, offset)
+	if errors.Is(err, sqlite.ErrReleasesNotFound) {
+		return nil, nil
+	}
 	if err != nil {
-		if errors.Is(err, sqlite.ErrReleasesNotFound) {
-			return nil
-		} else {
-			log.Fatal(err)
-		}
+		return nil, fmt.Errorf("error while getting releases of %d: %w", year, err)
 	}
-	rels := ConvertDbReleaseToModelRelease(releases)
-	return rels
+
+	return ConvertDbReleaseToModelRelease(releases), nil
 }
 
 func (h *HipHopService) GetAllYearSingles(year int, withCover bool) []models.Release {
@@ -130,16 +152,104 @@ func (h *HipHopService) GetAllYearSingles(year int, withCover bool) []models.Rel
 func (h *HipHopService) GetReleasesByDay(
 	year int,
 	month time.Month,
-	day, limit, offset int,
-) []models.Release {
-	releases, err := h.DbRepository.GetReleasesByDay(year, month, day, limit, offset)
+	day int,
+	releaseType models.ReleaseType,
+	limit, offset int,
+) ([]models.Release, error) {
+	releases, err := h.DbRepository.GetReleasesByDay(year, month, day, releaseType, limit, offset)
+	if errors.Is(err, sqlite.ErrReleasesNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting releases of %d-%02d-%02d: %w", year, month, day, err)
+	}
+
+	return ConvertDbReleaseToModelRelease(releases), nil
+}
+
+func (h *HipHopService) GetRelease(id int) (*models.Release, error) {
+	release, err := h.GetReleaseById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rels := ConvertDbReleaseToModelRelease([]*db.ReleaseDB{release})
+	return &rels[0], nil
+}
+
+func (h *HipHopService) SearchReleases(query string, limit, offset int) ([]models.Release, error) {
+	releases, err := h.DbRepository.SearchReleases(query, limit, offset)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrReleasesNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ConvertDbReleaseToModelRelease(releases), nil
+}
+
+func (h *HipHopService) GetReleasesByPeriod(
+	from, to time.Time,
+	limit, offset int,
+) ([]models.Release, error) {
+	releases, err := h.DbRepository.GetReleasesByPeriod(from, to, limit, offset)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrReleasesNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ConvertDbReleaseToModelRelease(releases), nil
+}
+
+// GetSavedReleases - страница личного списка пользователя, пустой список - nil без ошибки.
+func (h *HipHopService) GetSavedReleases(
+	userId int64,
+	byDate bool,
+	limit, offset int,
+) ([]models.Release, error) {
+	releases, err := h.DbRepository.GetSavedReleases(userId, byDate, limit, offset)
 	if err != nil {
 		if errors.Is(err, sqlite.ErrReleasesNotFound) {
-			return nil
-		} else {
-			log.Fatal(err)
+			return nil, nil
 		}
+		return nil, err
+	}
+
+	return ConvertDbReleaseToModelRelease(releases), nil
+}
+
+// AddManualRelease добавляет релиз, которого нет на HipHopDX,
+// исполнитель создается, если его еще нет в базе.
+func (h *HipHopService) AddManualRelease(release models.Release) (int, error) {
+	artistId, err := h.getOrAddArtist(release.Artist.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.DbRepository.AddManualRelease(release, artistId)
+}
+
+// SetReleaseArtist привязывает релиз к исполнителю artistName, создавая его при необходимости.
+func (h *HipHopService) SetReleaseArtist(releaseId int, artistName string) error {
+	artistId, err := h.getOrAddArtist(artistName)
+	if err != nil {
+		return err
+	}
+
+	return h.UpdateReleaseArtist(releaseId, artistId)
+}
+
+func (h *HipHopService) getOrAddArtist(artistName string) (int, error) {
+	artist, err := h.FindArtistByName(artistName)
+	if err == nil {
+		return artist.Id, nil
+	}
+	if !errors.Is(err, sqlite.ErrArtistNotFound) {
+		return 0, err
 	}
 
-	return ConvertDbReleaseToModelRelease(releases)
+	return h.AddArtist(artistName)
 }
@@ -17,9 +17,15 @@ import (
 type HipHopService interface {
 	FetchReleases(year int) ([]models.Release, error)
 	FetchSingles(year int) ([]models.Release, error)
+	FetchMonthReleases(year int, month time.Month) ([]models.Release, error)
 
-	GetMonthReleases(year int, month time.Month, limit, offset int) []models.Release
-	GetAllYearReleases(year int, limit, offset int) []models.Release
+	GetMonthReleases(
+		year int,
+		month time.Month,
+		releaseType models.ReleaseType,
+		limit, offset int,
+	) ([]models.Release, error)
+	GetAllYearReleases(year int, limit, offset int) ([]models.Release, error)
 	GetAllYearSingles(year int, withCover bool) []models.Release
 
 	Close()
@@ -43,15 +49,25 @@ type DbRepository interface {
 	ReleaseRepositoryInterface
 	ArtistsRepositoryInterface
 	CreateReleaseWithArtist(release models.Release) (int, error)
-	CreateMultiArtistsAndReleases(releases []models.Release) error
+	CreateMultiArtistsAndReleases(releases []models.Release) ([]models.Release, error)
 	UpdateReleaseCoverUrl(releaseId int, coverUrl string) error
+	AddUpdaterRun(run models.UpdaterRun) error
 	Close()
 }
 
+// ReleasesNotifier получает релизы, которые были впервые добавлены в базу
+// во время обновления, и ошибки обновления для администратора.
+type ReleasesNotifier interface {
+	NotifyFollowers(releases []models.Release)
+	NotifyUpdaterError(err error)
+}
+
 type Updater struct {
 	mu sync.Mutex
 	HipHopService
 	DbRepository
+	Notifier ReleasesNotifier
+	Window   YearsWindow
 }
 
 func NewUpdater(hipHopService HipHopService, dbRepo DbRepository) *Updater {
@@ -59,13 +75,19 @@ func NewUpdater(hipHopService HipHopService, dbRepo DbRepository) *Updater {
 		sync.Mutex{},
 		hipHopService,
 		dbRepo,
+		nil,
+		YearsWindow{Back: DefaultYearsBack},
 	}
 }
 
+// CurrentYears - года окна обновления на текущий момент.
+func (u *Updater) CurrentYears() []int {
+	return u.Window.For(time.Now())
+}
+
 func (u *Updater) StartUploadReleases(
 	ctx context.Context,
 	timeToUpdate time.Duration,
-	years []int,
 	withCover bool,
 ) {
 	log.Println("start updater on timer")
@@ -74,13 +96,14 @@ func (u *Updater) StartUploadReleases(
 	// sleep at first run
 	time.Sleep(30 * time.Second)
 
-	u.RefreshReleases(years)
+	// окно лет считается заново при каждом обновлении, чтобы с новым годом не менять настройки
+	u.RefreshReleases(u.CurrentYears())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			u.RefreshReleases(years)
+			u.RefreshReleases(u.CurrentYears())
 		case <-ctx.Done():
 			u.Close()
 		}
@@ -103,7 +126,7 @@ func (u *Updater) Close() {
 }
 
 func (u *Updater) CreateReleasesInDB(releases []models.Release) error {
-	if err := u.CreateMultiArtistsAndReleases(releases); err != nil {
+	if _, err := u.CreateMultiArtistsAndReleases(releases); err != nil {
 		return err
 	}
 	return nil
@@ -144,9 +167,13 @@ func (u *Updater) UpdateCoversInDB() error {
 
 func (u *Updater) RefreshReleases(years []int) {
 	log.Println("looking for new releases")
+	startedAt := time.Now()
+	newReleases := make([]models.Release, 0)
+	var errs []error
 	for _, year := range years {
 
 		allReleases := make([]models.Release, 0, 10)
+		var yearErrs []error
 
 		var wg sync.WaitGroup
 		wg.Add(2)
@@ -156,7 +183,10 @@ func (u *Updater) RefreshReleases(years []int) {
 			defer wg.Done()
 			newReleases, err := u.FetchReleases(year)
 			if err != nil {
-				log.Fatal(err)
+				u.mu.Lock()
+				yearErrs = append(yearErrs, fmt.Errorf("error while fetching releases of %d: %w", year, err))
+				u.mu.Unlock()
+				return
 			}
 			u.mu.Lock()
 			allReleases = append(allReleases, newReleases...)
@@ -168,7 +198,10 @@ func (u *Updater) RefreshReleases(years []int) {
 			defer wg.Done()
 			newSingles, err := u.FetchSingles(year)
 			if err != nil {
-				log.Fatal(err)
+				u.mu.Lock()
+				yearErrs = append(yearErrs, fmt.Errorf("error while fetching singles of %d: %w", year, err))
+				u.mu.Unlock()
+				return
 			}
 
 			u.mu.Lock()
@@ -178,16 +211,69 @@ func (u *Updater) RefreshReleases(years []int) {
 
 		// waiting and creating all releases in database
 		wg.Wait()
-		err := u.CreateMultiArtistsAndReleases(allReleases)
+		errs = append(errs, yearErrs...)
+		inserted, err := u.CreateMultiArtistsAndReleases(allReleases)
 		if err != nil {
-			log.Fatal(err)
+			// релизы других лет все равно сохраняем
+			errs = append(errs, fmt.Errorf("error while saving releases of %d: %w", year, err))
+			continue
 		}
-		log.Println("releases are updated")
+		newReleases = append(newReleases, inserted...)
+		log.Printf("releases are updated, %d new", len(inserted))
+	}
+
+	u.finishRefresh(startedAt, newReleases, errors.Join(errs...))
+}
+
+// RefreshMonth ищет новые релизы только за один месяц, например после исправлений на HipHopDX.
+func (u *Updater) RefreshMonth(year int, month time.Month) {
+	log.Printf("looking for new releases of %d-%02d", year, month)
+	startedAt := time.Now()
+
+	releases, err := u.FetchMonthReleases(year, month)
+	if err != nil {
+		u.finishRefresh(startedAt, nil, fmt.Errorf("error while fetching releases of %d-%02d: %w", year, month, err))
+		return
 	}
 
+	newReleases, err := u.CreateMultiArtistsAndReleases(releases)
+	if err != nil {
+		u.finishRefresh(startedAt, nil, fmt.Errorf("error while saving releases of %d-%02d: %w", year, month, err))
+		return
+	}
+	log.Printf("releases are updated, %d new", len(newReleases))
+
+	u.finishRefresh(startedAt, newReleases, nil)
+}
+
+// finishRefresh ищет обложки и уведомляет подписчиков о том, что успело сохраниться.
+// Запуск записывается, только если обновление прошло без ошибок, иначе ошибка уходит администратору.
+func (u *Updater) finishRefresh(startedAt time.Time, newReleases []models.Release, refreshErr error) {
 	// update covers after adding releases
 	err := u.UpdateCoversInDB()
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("error while updating covers: %s", err)
+	}
+
+	// notify followers only after covers, so the messages go out with artwork
+	if u.Notifier != nil && len(newReleases) != 0 {
+		u.Notifier.NotifyFollowers(newReleases)
+	}
+
+	if refreshErr != nil {
+		log.Printf("releases refresh failed: %s", refreshErr)
+		if u.Notifier != nil {
+			u.Notifier.NotifyUpdaterError(refreshErr)
+		}
+		return
+	}
+
+	err = u.AddUpdaterRun(models.UpdaterRun{
+		StartedAt:   startedAt,
+		FinishedAt:  time.Now(),
+		NewReleases: len(newReleases),
+	})
+	if err != nil {
+		log.Printf("error while saving updater run: %s", err)
 	}
 }
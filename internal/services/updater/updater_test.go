@@ -0,0 +1,110 @@
+package updater
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hip-hop-geek/internal/db"
+	"hip-hop-geek/internal/db/sqlite"
+	"hip-hop-geek/internal/models"
+)
+
+// stubHipHopService отдает релизы всех лет, кроме failYear
+type stubHipHopService struct {
+	HipHopService
+	failYear int
+}
+
+func (s *stubHipHopService) FetchReleases(year int) ([]models.Release, error) {
+	if year == s.failYear {
+		return nil, errors.New("hiphopdx is down")
+	}
+	return []models.Release{{Id: year, Title: "Album"}}, nil
+}
+
+func (s *stubHipHopService) FetchSingles(year int) ([]models.Release, error) {
+	return nil, nil
+}
+
+type stubDbRepository struct {
+	DbRepository
+	runs []models.UpdaterRun
+}
+
+func (s *stubDbRepository) CreateMultiArtistsAndReleases(
+	releases []models.Release,
+) ([]models.Release, error) {
+	return releases, nil
+}
+
+func (s *stubDbRepository) GetReleasesWithoutCover() ([]*db.ReleaseDB, error) {
+	return nil, sqlite.ErrReleasesNotFound
+}
+
+func (s *stubDbRepository) AddUpdaterRun(run models.UpdaterRun) error {
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+type stubNotifier struct {
+	released []models.Release
+	errs     []error
+}
+
+func (s *stubNotifier) NotifyFollowers(releases []models.Release) {
+	s.released = append(s.released, releases...)
+}
+
+func (s *stubNotifier) NotifyUpdaterError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func TestRefreshReleases(t *testing.T) {
+	t.Run("successful run is recorded", func(t *testing.T) {
+		repo := &stubDbRepository{}
+		notifier := &stubNotifier{}
+		updater := NewUpdater(&stubHipHopService{}, repo)
+		updater.Notifier = notifier
+
+		updater.RefreshReleases([]int{2025, 2026})
+		assert.Len(t, repo.runs, 1)
+		assert.Equal(t, 2, repo.runs[0].NewReleases)
+		assert.Empty(t, notifier.errs)
+	})
+
+	t.Run("failed year is reported instead of recorded", func(t *testing.T) {
+		repo := &stubDbRepository{}
+		notifier := &stubNotifier{}
+		updater := NewUpdater(&stubHipHopService{failYear: 2026}, repo)
+		updater.Notifier = notifier
+
+		updater.RefreshReleases([]int{2025, 2026})
+		assert.Empty(t, repo.runs)
+		assert.Len(t, notifier.errs, 1)
+		assert.Contains(t, notifier.errs[0].Error(), "2026")
+		// релизы удачных лет все равно сохраняются и рассылаются
+		assert.Len(t, notifier.released, 1)
+	})
+
+	t.Run("failed month is reported", func(t *testing.T) {
+		repo := &stubDbRepository{}
+		notifier := &stubNotifier{}
+		updater := NewUpdater(&failingMonthService{}, repo)
+		updater.Notifier = notifier
+
+		updater.RefreshMonth(2026, time.March)
+		assert.Empty(t, repo.runs)
+		assert.Len(t, notifier.errs, 1)
+	})
+}
+
+type failingMonthService struct {
+	HipHopService
+}
+
+func (s *failingMonthService) FetchMonthReleases(int, time.Month) ([]models.Release, error) {
+	return nil, errors.New("hiphopdx is down")
+}
@@ -0,0 +1,63 @@
+package updater
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultYearsBack - сколько прошлых лет обновляется, если окно не задано.
+const DefaultYearsBack = 1
+
+// YearsWindow - года, релизы которых ищет обновление. Если список Years пустой,
+// окно сдвигается вместе с текущим годом: от текущего минус Back до следующего,
+// куда HipHopDX заранее добавляет анонсы.
+type YearsWindow struct {
+	Years []int
+	Back  int
+}
+
+// ParseYearsWindow разбирает настройки окна: список лет через запятую
+// и количество прошлых лет для сдвигающегося окна. Пустые значения - окно по умолчанию.
+func ParseYearsWindow(years, back string) (YearsWindow, error) {
+	window := YearsWindow{Back: DefaultYearsBack}
+
+	if back = strings.TrimSpace(back); back != "" {
+		yearsBack, err := strconv.Atoi(back)
+		if err != nil || yearsBack < 0 {
+			return YearsWindow{}, fmt.Errorf("invalid years back %q", back)
+		}
+		window.Back = yearsBack
+	}
+
+	for _, value := range strings.Split(years, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return YearsWindow{}, fmt.Errorf("invalid year %q: %w", value, err)
+		}
+		window.Years = append(window.Years, year)
+	}
+	slices.Sort(window.Years)
+	window.Years = slices.Compact(window.Years)
+
+	return window, nil
+}
+
+func (w YearsWindow) For(now time.Time) []int {
+	if len(w.Years) != 0 {
+		return slices.Clone(w.Years)
+	}
+
+	years := make([]int, 0, w.Back+2)
+	for year := now.Year() - w.Back; year <= now.Year()+1; year++ {
+		years = append(years, year)
+	}
+	return years
+}
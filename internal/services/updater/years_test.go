@@ -0,0 +1,38 @@
+package updater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYearsWindow(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("sliding window by default", func(t *testing.T) {
+		window, err := ParseYearsWindow("", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2025, 2026, 2027}, window.For(now))
+		assert.Equal(t, []int{2026, 2027, 2028}, window.For(now.AddDate(1, 0, 0)))
+	})
+
+	t.Run("years back", func(t *testing.T) {
+		window, err := ParseYearsWindow("", "0")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2026, 2027}, window.For(now))
+	})
+
+	t.Run("explicit years", func(t *testing.T) {
+		window, err := ParseYearsWindow("2024, 2023,2024", "3")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2023, 2024}, window.For(now))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := ParseYearsWindow("2024,next", "")
+		assert.Error(t, err)
+		_, err = ParseYearsWindow("", "-1")
+		assert.Error(t, err)
+	})
+}
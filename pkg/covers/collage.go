@@ -0,0 +1,170 @@
+package covers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	CollageTileSize    = 320
+	CollageMaxTiles    = 10
+	CollageJpegQuality = 85
+
+	downloadTimeout = 10 * time.Second
+)
+
+var (
+	ErrNoCovers      = errors.New("no covers for collage")
+	placeholderColor = color.RGBA{R: 40, G: 40, B: 40, A: 255}
+)
+
+type Collage struct {
+	client   *http.Client
+	tileSize int
+}
+
+func NewCollage() *Collage {
+	return &Collage{
+		client:   &http.Client{Timeout: downloadTimeout},
+		tileSize: CollageTileSize,
+	}
+}
+
+// MakeCollage скачивает обложки и собирает из них сетку в jpeg.
+// Порядок плиток совпадает с порядком urls: слева направо, сверху вниз.
+// Пустой url или обложка, которую не удалось скачать, заменяются заглушкой.
+func (c *Collage) MakeCollage(urls []string) ([]byte, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoCovers
+	}
+	if len(urls) > CollageMaxTiles {
+		urls = urls[:CollageMaxTiles]
+	}
+
+	tiles := make([]image.Image, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		if url == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			img, err := c.download(url)
+			if err != nil {
+				return
+			}
+			tiles[i] = img
+		}(i, url)
+	}
+	wg.Wait()
+
+	downloaded := 0
+	for _, tile := range tiles {
+		if tile != nil {
+			downloaded++
+		}
+	}
+	if downloaded == 0 {
+		return nil, ErrNoCovers
+	}
+
+	cols, rows := GridSize(len(tiles))
+	collage := image.NewRGBA(image.Rect(0, 0, cols*c.tileSize, rows*c.tileSize))
+	draw.Draw(collage, collage.Bounds(), &image.Uniform{placeholderColor}, image.Point{}, draw.Src)
+
+	for i, tile := range tiles {
+		if tile == nil {
+			continue
+		}
+		x, y := (i%cols)*c.tileSize, (i/cols)*c.tileSize
+		rect := image.Rect(x, y, x+c.tileSize, y+c.tileSize)
+		draw.Draw(collage, rect, ScaleToSquare(tile, c.tileSize), image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, collage, &jpeg.Options{Quality: CollageJpegQuality})
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding collage: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Collage) download(url string) (image.Image, error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover %s responded with status %d", url, resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding cover %s: %w", url, err)
+	}
+
+	return img, nil
+}
+
+// GridSize подбирает почти квадратную сетку: 4 плитки - 2x2, 9 - 3x3, 10 - 4x3.
+func GridSize(tiles int) (int, int) {
+	if tiles <= 0 {
+		return 0, 0
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(tiles))))
+	rows := (tiles + cols - 1) / cols
+	return cols, rows
+}
+
+// ScaleToSquare обрезает картинку до квадрата по центру и масштабирует до size x size,
+// каждый пиксель результата - среднее по соответствующей области исходника.
+func ScaleToSquare(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	offsetX := bounds.Min.X + (bounds.Dx()-side)/2
+	offsetY := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		srcY0 := offsetY + y*side/size
+		srcY1 := max(offsetY+(y+1)*side/size, srcY0+1)
+		for x := 0; x < size; x++ {
+			srcX0 := offsetX + x*side/size
+			srcX1 := max(offsetX+(x+1)*side/size, srcX0+1)
+
+			var r, g, b, a, count uint32
+			for sy := srcY0; sy < srcY1; sy++ {
+				for sx := srcX0; sx < srcX1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+					count++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / count >> 8),
+				G: uint8(g / count >> 8),
+				B: uint8(b / count >> 8),
+				A: uint8(a / count >> 8),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,81 @@
+package covers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGridSize(t *testing.T) {
+	cases := []struct {
+		tiles int
+		cols  int
+		rows  int
+	}{
+		{tiles: 1, cols: 1, rows: 1},
+		{tiles: 2, cols: 2, rows: 1},
+		{tiles: 4, cols: 2, rows: 2},
+		{tiles: 5, cols: 3, rows: 2},
+		{tiles: 9, cols: 3, rows: 3},
+		{tiles: 10, cols: 4, rows: 3},
+	}
+
+	for _, tc := range cases {
+		cols, rows := GridSize(tc.tiles)
+		assert.Equal(t, tc.cols, cols, "cols for %d tiles", tc.tiles)
+		assert.Equal(t, tc.rows, rows, "rows for %d tiles", tc.tiles)
+	}
+}
+
+func TestScaleToSquare(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for x := 0; x < 200; x++ {
+		for y := 0; y < 100; y++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	dst := ScaleToSquare(src, 10)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(5, 5))
+}
+
+func TestMakeCollage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		cover := image.NewRGBA(image.Rect(0, 0, 50, 50))
+		png.Encode(w, cover)
+	}))
+	defer server.Close()
+
+	t.Run("grid with placeholders", func(t *testing.T) {
+		collage := &Collage{client: server.Client(), tileSize: 20}
+		data, err := collage.MakeCollage([]string{
+			server.URL + "/1.png",
+			"",
+			server.URL + "/missing.png",
+			server.URL + "/2.png",
+		})
+		assert.NoError(t, err)
+
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 40, 40), img.Bounds())
+	})
+
+	t.Run("no covers downloaded", func(t *testing.T) {
+		collage := &Collage{client: server.Client(), tileSize: 20}
+		_, err := collage.MakeCollage([]string{"", server.URL + "/missing.png"})
+		assert.ErrorIs(t, err, ErrNoCovers)
+	})
+}